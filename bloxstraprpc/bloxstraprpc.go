@@ -41,8 +41,14 @@ const (
 )
 
 type Activity struct {
-	presence drpc.Activity
-	client   *drpc.Client
+	// OnUpdate, if set, is called with the Activity's state
+	// whenever the presence has changed, regardless of whether
+	// Discord is connected.
+	OnUpdate func(State)
+
+	presence  drpc.Activity
+	client    *drpc.Client
+	connected bool
 
 	gameTime    time.Time
 	teleporting bool
@@ -169,5 +175,10 @@ func (a *Activity) handleGameLeave() error {
 
 	slog.Info("Handled GameLeave")
 
+	a.update()
+	if !a.connected {
+		return nil
+	}
+
 	return a.client.SetActivity(a.presence)
 }
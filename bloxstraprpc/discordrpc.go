@@ -10,12 +10,18 @@ import (
 func (a *Activity) Connect() error {
 	slog.Info("Connecting to Discord RPC")
 
-	return a.client.Connect()
+	if err := a.client.Connect(); err != nil {
+		return err
+	}
+
+	a.connected = true
+	return nil
 }
 
 func (a *Activity) Close() error {
 	slog.Info("Closing Discord RPC")
 
+	a.connected = false
 	return a.client.Close()
 }
 
@@ -91,6 +97,11 @@ func (a *Activity) UpdateGamePresence(initial bool) error {
 		}
 	}
 
+	a.update()
+	if !a.connected {
+		return nil
+	}
+
 	slog.Info("Updating Discord Rich Presence", "presence", a.presence)
 
 	return a.client.SetActivity(a.presence)
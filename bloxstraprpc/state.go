@@ -0,0 +1,64 @@
+package bloxstraprpc
+
+import (
+	"time"
+)
+
+// State is a representation of the Activity's current game and
+// rich presence, meant to be exported to other programs such as
+// stream overlays.
+type State struct {
+	Playing    bool      `json:"playing"`
+	Details    string    `json:"details,omitempty"`
+	State      string    `json:"state,omitempty"`
+	Started    time.Time `json:"started"`
+	LargeImage string    `json:"large_image,omitempty"`
+	LargeText  string    `json:"large_text,omitempty"`
+	SmallImage string    `json:"small_image,omitempty"`
+	SmallText  string    `json:"small_text,omitempty"`
+	Server     string    `json:"server"`
+	PlaceID    string    `json:"place_id,omitempty"`
+	UniverseID string    `json:"universe_id,omitempty"`
+	JobID      string    `json:"job_id,omitempty"`
+}
+
+func (s ServerType) String() string {
+	switch s {
+	case Public:
+		return "public"
+	case Private:
+		return "private"
+	case Reserved:
+		return "reserved"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns the Activity's current state.
+func (a *Activity) State() State {
+	s := State{
+		Playing:    !a.gameTime.IsZero(),
+		Details:    a.presence.Details,
+		State:      a.presence.State,
+		Started:    a.gameTime,
+		Server:     a.server.String(),
+		PlaceID:    a.placeID,
+		UniverseID: a.universeID,
+		JobID:      a.jobID,
+	}
+
+	if as := a.presence.Assets; as != nil {
+		s.LargeImage, s.LargeText = as.LargeImage, as.LargeText
+		s.SmallImage, s.SmallText = as.SmallImage, as.SmallText
+	}
+
+	return s
+}
+
+// update calls the Activity's OnUpdate with the current state, if set.
+func (a *Activity) update() {
+	if a.OnUpdate != nil {
+		a.OnUpdate(a.State())
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	bsrpc "github.com/vinegarhq/vinegar/bloxstraprpc"
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/events"
+)
+
+// ActivityPath returns the path of the file the Binary's
+// activity is exported to, if enabled by the configuration.
+func (b *Binary) ActivityPath() string {
+	return filepath.Join(dirs.Runtime, "activity-"+strings.ToLower(b.Alias)+".json")
+}
+
+// ExportActivity writes the given activity state to the Binary's
+// activity file and sends it to the events stream.
+func (b *Binary) ExportActivity(s bsrpc.State) {
+	b.Events.Send(events.Event{Type: events.Activity, Activity: s})
+
+	if err := writeActivity(b.ActivityPath(), s); err != nil {
+		slog.Error("Failed to export activity", "error", err)
+	}
+}
+
+// writeActivity writes the activity state to the named file, replacing
+// it only once fully written so that readers never see a partial state.
+func writeActivity(name string, s bsrpc.State) error {
+	if err := dirs.Mkdirs(filepath.Dir(name)); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
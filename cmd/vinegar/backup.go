@@ -0,0 +1,351 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/wine"
+)
+
+// BackupExt is the extension of Wineprefix backup archives.
+const BackupExt = ".tar.gz"
+
+var ErrBadBackup = errors.New("invalid backup archive")
+
+// backupHives is a list of the Wineprefix's registry hives, which are
+// replaced along with the users directory when restoring a backup.
+var backupHives = []string{"system.reg", "user.reg", "userdef.reg"}
+
+// backupPrefix returns the prefix of the names of the Binary's backups.
+func (b *Binary) backupPrefix() string {
+	return strings.ToLower(b.Type.String()) + "-"
+}
+
+// Backup archives the Binary's Wineprefix to a new timestamped archive
+// within dirs.Backups, skipping the paths excluded by the configuration,
+// returning the path of the archive and the amount of bytes excluded.
+func (b *Binary) Backup() (string, int64, error) {
+	dir := b.Prefix.Dir()
+	if _, err := os.Stat(filepath.Join(dir, "system.reg")); err != nil {
+		return "", 0, fmt.Errorf("%w: %s", wine.ErrPrefixNotInit, dir)
+	}
+
+	if PrefixRunning(dir) {
+		slog.Warn("Wineprefix is in use, the backup may not contain recent registry changes")
+	}
+
+	if err := dirs.Mkdirs(dirs.Backups); err != nil {
+		return "", 0, err
+	}
+
+	name := filepath.Join(dirs.Backups,
+		b.backupPrefix()+time.Now().Format("20060102-150405")+BackupExt)
+	tmp := name + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", 0, err
+	}
+
+	slog.Info("Backing up Wineprefix", "dir", dir, "path", name)
+
+	excluded, err := writeBackup(f, dir, b.GlobalConfig.BackupExcluded)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", excluded, err
+	}
+
+	return name, excluded, nil
+}
+
+// writeBackup writes a gzip compressed tar archive of the named directory
+// to w, skipping the slash-separated paths relative to the directory that
+// are excluded, returning the amount of bytes excluded.
+func writeBackup(w io.Writer, dir string, excluded func(string) bool) (skipped int64, err error) {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if excluded(rel) {
+			size, _ := DirSize(path)
+			skipped += size
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		switch {
+		case fi.Mode()&fs.ModeSymlink != 0:
+			// Symlinks such as the Wineprefix's dosdevices
+			// are kept as-is, and never followed.
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		case !fi.Mode().IsRegular() && !fi.IsDir():
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return skipped, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return skipped, err
+	}
+
+	return skipped, zw.Close()
+}
+
+// Restore replaces the Binary's Wineprefix users directory and registry
+// hives with those of the named backup, and restores the backup's other
+// files over the Wineprefix. The named backup may be the name of an archive
+// within dirs.Backups, with or without it's extension, or a path to one.
+//
+// The archive is read in full before anything is removed, to ensure that
+// a corrupted backup cannot leave the Wineprefix without it's registry.
+func (b *Binary) Restore(name string) (string, error) {
+	dir := b.Prefix.Dir()
+	if PrefixRunning(dir) {
+		return "", errors.New("wineprefix is in use, refusing to restore; stop Roblox or run kill first")
+	}
+
+	if !strings.ContainsRune(name, filepath.Separator) {
+		name = filepath.Join(dirs.Backups, name)
+	}
+	if !strings.HasSuffix(name, BackupExt) {
+		name += BackupExt
+	}
+
+	if err := readBackup(name, dir, nil); err != nil {
+		return "", fmt.Errorf("verify %s: %w", name, err)
+	}
+
+	slog.Info("Restoring Wineprefix", "dir", dir, "path", name)
+
+	remove := []string{filepath.Join(dir, "drive_c", "users")}
+	for _, h := range backupHives {
+		remove = append(remove, filepath.Join(dir, h))
+	}
+	for _, p := range remove {
+		if err := os.RemoveAll(p); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	return name, readBackup(name, dir, extractBackupFile)
+}
+
+// readBackup reads every entry of the named backup archive, calling fn
+// with each entry's destination within the named directory, if fn is set.
+func readBackup(name, dir string, fn func(string, *tar.Header, io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(dest, dir+string(filepath.Separator)) {
+			return fmt.Errorf("%w: %s is outside the wineprefix", ErrBadBackup, hdr.Name)
+		}
+
+		if fn == nil {
+			_, err = io.Copy(io.Discard, tr)
+		} else {
+			err = fn(dest, hdr, tr)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// extractBackupFile writes the backup archive entry read from r
+// to the named destination.
+func extractBackupFile(dest string, hdr *tar.Header, r io.Reader) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dest, 0o755)
+	case tar.TypeSymlink:
+		os.Remove(dest)
+		return os.Symlink(hdr.Linkname, dest)
+	case tar.TypeReg:
+	default:
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	// Avoid writing through an existing symlink.
+	os.Remove(dest)
+
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(dest, hdr.ModTime, hdr.ModTime)
+}
+
+// Backups returns the paths of the Binary's backups, oldest first.
+func (b *Binary) Backups() ([]string, error) {
+	backups, err := filepath.Glob(filepath.Join(dirs.Backups, b.backupPrefix()+"*"+BackupExt))
+	if err != nil {
+		return nil, err
+	}
+
+	// Backup names are timestamped in a sortable format.
+	sort.Strings(backups)
+
+	return backups, nil
+}
+
+// BackupCommand parses the backup subcommand's arguments and either
+// lists the Binary's backups, or backs up the Binary's Wineprefix.
+func (b *Binary) BackupCommand(args ...string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	list := flags.Bool("list", false, "list existing backups instead of creating one")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *list {
+		backups, err := b.Backups()
+		if err != nil {
+			return err
+		}
+
+		for _, p := range backups {
+			fi, err := os.Stat(p)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s\t%s\t%s\n", strings.TrimSuffix(filepath.Base(p), BackupExt),
+				HumanSize(fi.Size()), fi.ModTime().Format(time.DateTime))
+		}
+
+		return nil
+	}
+
+	name, excluded, err := b.Backup()
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Backed up wineprefix to %s (%s, %s excluded)\n",
+		name, HumanSize(fi.Size()), HumanSize(excluded))
+	return nil
+}
+
+// RestoreCommand restores the Binary's Wineprefix from the backup
+// named by the restore subcommand's only argument.
+func (b *Binary) RestoreCommand(args ...string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	name, err := b.Restore(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Restored wineprefix from", name)
+	return nil
+}
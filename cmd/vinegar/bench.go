@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type benchPhase struct {
+	name string
+	dur  time.Duration
+}
+
+// Bench records the time spent in each phase of launching a Binary.
+// All methods of a nil Bench are no-ops, to allow it to be used
+// unconditionally during a normal launch.
+type Bench struct {
+	start  time.Time
+	last   time.Time
+	phases []benchPhase
+}
+
+// NewBench returns a new Bench with it's first phase starting now.
+func NewBench() *Bench {
+	now := time.Now()
+	return &Bench{start: now, last: now}
+}
+
+// Mark records the time elapsed since the previous mark as the named phase.
+func (bn *Bench) Mark(name string) {
+	if bn == nil {
+		return
+	}
+
+	now := time.Now()
+	bn.phases = append(bn.phases, benchPhase{name, now.Sub(bn.last)})
+	bn.last = now
+}
+
+// Report writes a breakdown of the recorded phases to w.
+func (bn *Bench) Report(w io.Writer) {
+	if bn == nil {
+		return
+	}
+
+	total := bn.last.Sub(bn.start)
+
+	fmt.Fprintln(w, "Launch breakdown:")
+	for _, p := range bn.phases {
+		pct := 0.0
+		if total > 0 {
+			pct = float64(p.dur) / float64(total) * 100
+		}
+		fmt.Fprintf(w, "* %s: %s (%.1f%%)\n", p.name, p.dur.Round(time.Millisecond), pct)
+	}
+	fmt.Fprintf(w, "* Total: %s\n", total.Round(time.Millisecond))
+}
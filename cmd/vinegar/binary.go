@@ -6,10 +6,16 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"maps"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -19,6 +25,8 @@ import (
 	bsrpc "github.com/vinegarhq/vinegar/bloxstraprpc"
 	"github.com/vinegarhq/vinegar/config"
 	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/events"
+	"github.com/vinegarhq/vinegar/internal/journal"
 	"github.com/vinegarhq/vinegar/internal/state"
 	"github.com/vinegarhq/vinegar/roblox"
 	boot "github.com/vinegarhq/vinegar/roblox/bootstrapper"
@@ -29,16 +37,54 @@ import (
 
 const timeout = 6 * time.Second
 
+// WineLogPrefix is the prefix of the names of the log
+// files holding Wine's output when it is logged separately.
+const WineLogPrefix = "wine-"
+
+// logPollInterval is how often the Roblox log directory is
+// listed when polling for the Roblox log file.
+const logPollInterval = 250 * time.Millisecond
+
+// PollFilesystems is a list of filesystem types on which file events
+// are not reliably delivered, where the Roblox log file is polled for.
+// FUSE filesystems, such as 'fuse.sshfs', are always polled.
+var PollFilesystems = []string{
+	"nfs", "nfs4", "cifs", "smb3", "9p", "virtiofs", "overlay",
+}
+
+// prefixInitRetries is the amount of times Wineprefix initialization
+// is retried after a transient failure.
+const prefixInitRetries = 2
+
+// startEnv is the environment Vinegar was started with, used to
+// determine which variables were added or changed for Roblox.
+var startEnv = os.Environ()
+
+// sensitiveEnv is a list of substrings that, when contained in an environment
+// variable's name, will have the variable's value redacted from logs.
+var sensitiveEnv = []string{"TOKEN", "SECRET", "PASSW", "COOKIE", "CREDENTIAL", "KEY", "AUTH"}
+
+var (
+	ErrNoAVX          = errors.New("cpu does not support avx")
+	ErrAlreadyRunning = errors.New("roblox is already running, enable multiple_instances to run more than one")
+	ErrWineMismatch   = errors.New("wine version differs from the wineprefix's")
+)
+
 const (
 	DialogUseBrowser = "WebView/InternalBrowser is broken, please use the browser for the action that you were doing."
 	DialogQuickLogin = "WebView/InternalBrowser is broken, use Quick Log In to authenticate ('Log In With Another Device' button)"
 	DialogFailure    = "Vinegar experienced an error:\n%s"
+	DialogRegistry   = "The Wineprefix registry appears to be corrupted:\n%s\n\nAttempt to repair it?"
 	DialogNoAVX      = "Warning: Your CPU does not support AVX. While some people may be able to run without it, most are not able to. VinegarHQ cannot provide support for your installation. Continue?"
 )
 
 type Binary struct {
 	// Only initialized in Main
-	Splash *splash.Splash
+	Splash  *splash.Splash
+	Text    atomic.Pointer[boot.TextReporter] // Set when the splash has crashed
+	Events  *events.Server
+	Bench   *Bench
+	Journal *journal.Conn
 
 	GlobalState *state.State
 	State       *state.Binary
@@ -52,10 +98,19 @@ type Binary struct {
 	Prefix *wine.Prefix
 	Type   roblox.BinaryType
 	Deploy *boot.Deployment
+	Place  string // Place ID of the experience being joined, if any
 
 	// Logging
-	Auth     bool
-	Activity bsrpc.Activity
+	Auth          bool
+	WebViewBroken bool
+	Activity      bsrpc.Activity
+
+	// Set when the WebView installation was deferred to
+	// after Roblox has launched.
+	WebViewDeferred bool
+
+	diagnosedMu sync.Mutex
+	diagnosed   map[string]bool
 }
 
 func BinaryPrefixDir(bt roblox.BinaryType) string {
@@ -84,6 +139,7 @@ func NewBinary(bt roblox.BinaryType, cfg *config.Config) (*Binary, error) {
 	if err != nil {
 		return nil, fmt.Errorf("new prefix %s: %w", bt, err)
 	}
+	pfx.Server = bcfg.Wineserver
 
 	os.Setenv("GAMEID", "ulwgl-roblox")
 
@@ -105,33 +161,132 @@ func NewBinary(bt roblox.BinaryType, cfg *config.Config) (*Binary, error) {
 
 func (b *Binary) Main(args ...string) error {
 	b.Splash = splash.New(&b.GlobalConfig.Splash)
-	b.Config.Env.Setenv()
 
-	logFile, err := LogFile(b.Type.String())
-	if err != nil {
-		return fmt.Errorf("create log file: %w", err)
+	if b.Type == roblox.Player && !b.GlobalConfig.MultipleInstances && !InstallOnly &&
+		CommRunning(b.Type.Executable()) {
+		if err := b.FocusWindow(); err != nil {
+			slog.Warn("Could not focus existing Roblox window", "error", err)
+			return ErrAlreadyRunning
+		}
+
+		slog.Info("Roblox is already running, focused existing window")
+		return nil
+	}
+
+	if err := b.SetupEnv(); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stderr
+	if Quiet {
+		out = io.Discard
+	}
+
+	if LogPath != "-" {
+		logFile, err := LogFile(b.Type.String())
+		if err != nil {
+			return fmt.Errorf("create log file: %w", err)
+		}
+		defer logFile.Close()
+
+		out = io.MultiWriter(os.Stderr, logFile)
+		if Quiet {
+			out = logFile
+			SetQuietLogger(logFile)
+		}
+		defer func() {
+			b.Splash.LogPath = logFile.Name()
+		}()
 	}
-	defer logFile.Close()
 
-	out := io.MultiWriter(os.Stderr, logFile)
 	b.Prefix.Stderr = out
 	b.Prefix.Stdout = out
 	log.SetOutput(out)
-	defer func() {
-		b.Splash.LogPath = logFile.Name()
-	}()
+
+	if b.GlobalConfig.WineLog {
+		wineLog, err := WineLogFile(b.Type.String())
+		if err != nil {
+			return fmt.Errorf("create wine log file: %w", err)
+		}
+		defer wineLog.Close()
+
+		b.Prefix.Stderr = wineLog
+	}
+
+	if b.GlobalConfig.Journal {
+		j, err := journal.Dial()
+		if err != nil {
+			slog.Error("Failed to connect to the systemd journal", "error", err)
+		} else {
+			defer j.Close()
+			b.Journal = j
+
+			// The default handler writes to the log package, which would
+			// be redirected to the new handler, hence replace it.
+			h := slog.Default().Handler()
+			if !Quiet {
+				h = slog.NewTextHandler(out, nil)
+			}
+			slog.SetDefault(slog.New(teeHandler{h, journal.NewHandler(j, "vinegar")}))
+		}
+	}
+
+	if err := b.SetupSharedPrefix(); err != nil {
+		return fmt.Errorf("setup shared wineprefix: %w", err)
+	}
 
 	firstRun := false
 	if _, err := os.Stat(filepath.Join(b.Prefix.Dir(), "drive_c", "windows")); err != nil {
 		firstRun = true
 	}
 
+	if err := CheckOwnership(b.Prefix.Dir()); err != nil {
+		return err
+	}
+
+	if b.GlobalConfig.GPUProbe {
+		res, err := ProbeGPU()
+		if err != nil {
+			return fmt.Errorf("%w (disable gpu_probe to skip this check)", err)
+		}
+		slog.Info("Probed graphics stack", "result", res)
+	}
+
+	for _, c := range sysinfo.Cards {
+		if i := FindGPUIssue(c); i != nil {
+			slog.Warn("Graphics card is known to be rejected by Roblox",
+				"card", c, "reason", i.Reason, "workaround", i.Workaround)
+		}
+	}
+
+	if _, err := checkGamepads(b.GlobalConfig); err != nil {
+		slog.Warn("Gamepads will not work in Roblox", "error", err)
+	}
+
+	nofile, err := RaiseNofile()
+	if err != nil {
+		slog.Warn("Wine and Roblox may run out of file descriptors", "error", err)
+	} else {
+		slog.Info("Open file limit", "nofile", nofile)
+	}
+
+	b.SetupSync(nofile)
+
 	if firstRun && !sysinfo.CPU.AVX {
-		b.Splash.Dialog(DialogNoAVX, false)
-		slog.Warn("Running roblox without AVX, Roblox will most likely fail to run!")
+		if err := b.HandleNoAVX(); err != nil {
+			return err
+		}
 	}
 
 	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Splash window crashed, reporting progress as text",
+					"panic", r, "stack", string(debug.Stack()))
+				b.Text.Store(&boot.TextReporter{W: os.Stderr})
+			}
+		}()
+
 		err := b.Splash.Run()
 		if errors.Is(splash.ErrClosed, err) {
 			slog.Warn("Splash window closed!")
@@ -143,47 +298,90 @@ func (b *Binary) Main(args ...string) error {
 		}
 
 		// The splash window didn't close cleanly (ErrClosed), an
-		// internal error occured.
+		// internal error occured; continue without it.
 		if err != nil {
-			log.Fatalf("splash: %s", err)
+			slog.Error("Splash window failed", "error", err)
 		}
 	}()
 
+	b.Bench.Mark("Startup")
+
 	// Command-line flag vs wineprefix initialized
 	if firstRun || FirstRun {
 		slog.Info("Initializing wineprefix", "dir", b.Prefix.Dir())
-		b.Splash.SetMessage("Initializing wineprefix")
+		b.SetMessage("Initializing wineprefix")
 
-		var err error
-		switch b.Type {
-		case roblox.Player:
-			err = b.Prefix.Init()
-		case roblox.Studio:
-			// Studio accepts all DPIs except the default, which is 96.
-			// Technically this is 'initializing wineprefix', as SetDPI calls Wine which
-			// automatically create the Wineprefix.
-			err = b.Prefix.SetDPI(97)
+		if err := b.CheckPrefixFilesystem(); err != nil {
+			return fmt.Errorf("check prefix filesystem: %w", err)
 		}
 
-		if err != nil {
+		if err := b.InitPrefix(); err != nil {
 			return fmt.Errorf("failed to init %s prefix: %w", b.Type, err)
 		}
+	}
+
+	if err := b.CheckWineVersion(); err != nil {
+		return err
+	}
 
-		if err := b.InstallWebView(); err != nil {
-			return fmt.Errorf("failed to install webview: %w", err)
+	if b.GlobalConfig.RegistryCheck {
+		if err := b.CheckRegistry(); err != nil {
+			return err
 		}
 	}
 
-	// Modify and handle the protocol uri channel
+	if ReinitWebView {
+		if err := b.ReinitWebView(); err != nil {
+			return fmt.Errorf("failed to reinitialize webview: %w", err)
+		}
+	} else if err := b.SetupWebView(); err != nil {
+		return fmt.Errorf("failed to install webview: %w", err)
+	}
+
+	if err := b.SetupProfile(Profile); err != nil {
+		return fmt.Errorf("setup profile: %w", err)
+	}
+
+	if err := b.InjectCookie(); err != nil {
+		return fmt.Errorf("inject cookie: %w", err)
+	}
+
+	b.Bench.Mark("Wineprefix check")
+
+	var uriChannel string
 	if len(args) == 1 {
-		b.HandleProtocolURI(args[0])
+		uriChannel = URIChannel(args[0])
+		b.Place = URIPlace(args[0])
 	}
 
-	b.Splash.SetDesc(b.Config.Channel)
+	b.Config.Channel = config.ResolveChannel(Channel, uriChannel,
+		b.Config.Channel, b.GlobalConfig.Channel)
+	slog.Info("Using channel", "channel", b.Config.Channel)
+
+	b.SetDesc(b.Config.Channel)
 
-	if err := b.Setup(); err != nil {
+	res, err := b.Setup()
+	if err != nil {
 		return fmt.Errorf("failed to setup roblox: %w", err)
 	}
+	slog.Info("Setup complete", "result", res)
+
+	b.Bench.Mark("Setup and verification")
+
+	if InstallOnly {
+		slog.Info("Installation is ready, not launching", "guid", res.Version)
+		b.Splash.Close()
+		return nil
+	}
+
+	if b.Type == roblox.Studio && b.GlobalConfig.StudioIsolation &&
+		CommRunning(b.Type.Executable()) {
+		cleanup, err := b.IsolateInstance()
+		if err != nil {
+			return fmt.Errorf("isolate instance: %w", err)
+		}
+		defer cleanup()
+	}
 
 	if err := b.Run(args...); err != nil {
 		return fmt.Errorf("failed to run roblox: %w", err)
@@ -192,21 +390,284 @@ func (b *Binary) Main(args ...string) error {
 	return nil
 }
 
-func (b *Binary) HandleProtocolURI(mime string) {
+// SetupEnv applies the command-line preset and environment profile to
+// the Binary's configuration, and sets the Binary's environment.
+func (b *Binary) SetupEnv() error {
+	if Preset != "" {
+		if err := b.Config.ApplyPreset(Preset); err != nil {
+			return err
+		}
+
+		slog.Info("Using preset", "name", Preset, "channel", b.Config.Channel)
+	}
+
+	if EnvProfile != "" {
+		e, err := b.GlobalConfig.EnvProfile(EnvProfile)
+		if err != nil {
+			return err
+		}
+
+		slog.Info("Using environment profile", "name", EnvProfile)
+		if b.Config.Env == nil {
+			b.Config.Env = make(config.Environment)
+		}
+		maps.Copy(b.Config.Env, e)
+	}
+
+	b.ScrubSteamEnv()
+	b.Config.Env.Setenv()
+	return nil
+}
+
+// ScrubSteamEnv removes the configured Steam environment variables if
+// Vinegar was launched by Steam and the Binary does not use Proton,
+// which expects them. Variables set by the global configuration's
+// environment are kept.
+func (b *Binary) ScrubSteamEnv() {
+	if !config.InSteam() || len(b.GlobalConfig.SteamEnv) == 0 {
+		return
+	}
+
+	if root := strings.ToLower(b.Config.WineRoot); b.Prefix.Proton() ||
+		strings.Contains(root, "proton") || strings.Contains(root, "ulwgl") {
+		slog.Info("Launched by Steam, keeping Steam environment for Proton")
+		return
+	}
+
+	s := config.ScrubEnv(b.GlobalConfig.SteamEnv...)
+	if len(s) == 0 {
+		return
+	}
+	slog.Info("Launched by Steam, removed Steam environment", "variables", s)
+
+	for _, name := range s {
+		if v, ok := b.GlobalConfig.Env[name]; ok {
+			os.Setenv(name, v)
+		}
+	}
+}
+
+// SetupSync sets the Wine synchronization primitive environment variables
+// according to the configuration's sync mode. When automatic, fsync is
+// preferred if the kernel supports it, otherwise esync if the given open
+// file limit is high enough, otherwise neither.
+func (b *Binary) SetupSync(nofile uint64) {
+	mode := b.GlobalConfig.Sync
+	if mode == "" {
+		return
+	}
+
+	if mode == "auto" {
+		switch {
+		case sysinfo.Futex2:
+			mode = "fsync"
+		case nofile >= NofileSafe:
+			mode = "esync"
+		default:
+			mode = "none"
+		}
+	}
+
+	fsync, esync := "0", "0"
+	switch mode {
+	case "fsync":
+		fsync = "1"
+	case "esync":
+		esync = "1"
+	}
+
+	slog.Info("Using Wine synchronization", "sync", mode, "kernel", sysinfo.Kernel)
+	os.Setenv("WINEFSYNC", fsync)
+	os.Setenv("WINEESYNC", esync)
+}
+
+// HandleNoAVX decides whether to continue running Roblox on a CPU without
+// AVX support, based on the configuration's no_avx mode. When asking, the
+// user will only be asked if a dialog can be shown, otherwise Vinegar will
+// continue with a warning, as to never block non-interactive launches.
+func (b *Binary) HandleNoAVX() error {
+	switch b.GlobalConfig.NoAVX {
+	case "fail":
+		return ErrNoAVX
+	case "ask":
+		if !Interactive(b.GlobalConfig) {
+			slog.Warn("Not asking to continue without AVX in a non-interactive launch")
+			break
+		}
+
+		if !b.Splash.Dialog(DialogNoAVX, true) {
+			return ErrNoAVX
+		}
+	}
+
+	slog.Warn("Running roblox without AVX, Roblox will most likely fail to run!")
+	return nil
+}
+
+// Interactive determines if dialogs can be shown to the user, which
+// requires the splash to be enabled and a display to be available.
+func Interactive(cfg *config.Config) bool {
+	return cfg.Splash.Enabled &&
+		(os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "")
+}
+
+// stageMessages are the splash messages shown when a stage begins.
+var stageMessages = map[boot.Stage]string{
+	boot.StageVerify:   "Verifying",
+	boot.StageDownload: "Downloading",
+	boot.StageExtract:  "Extracting",
+}
+
+// Report implements [boot.Reporter], forwarding the progress of
+// the stage to the splash window and the events stream.
+func (b *Binary) Report(stage boot.Stage, current, total int, message string) {
+	if r := b.Text.Load(); r != nil {
+		r.Report(stage, current, total, message)
+	}
+
+	if current == 0 {
+		b.SetMessage(stageMessages[stage] + " " + b.Alias)
+	}
+
+	if total > 0 {
+		b.SetProgress(float32(current) / float32(total))
+	}
+}
+
+// CheckRegistry checks the Binary's Wineprefix registry for corruption,
+// and if it is found to be corrupted, offers to update the Wineprefix,
+// which has Wine rewrite the registry. Without a dialog, only a warning
+// is logged, as the registry may still be usable.
+func (b *Binary) CheckRegistry() error {
+	err := b.Prefix.CheckRegistry()
+	if err == nil || !errors.Is(err, wine.ErrRegistryCorrupt) {
+		return err
+	}
+
+	slog.Warn("Wineprefix registry is corrupted", "error", err)
+
+	if !Interactive(b.GlobalConfig) ||
+		!b.Splash.Dialog(fmt.Sprintf(DialogRegistry, err), true) {
+		slog.Warn("Not repairing Wineprefix registry, run 'vinegar " +
+			strings.ToLower(b.Type.String()) + " repair' if Roblox misbehaves")
+		return nil
+	}
+
+	b.SetMessage("Repairing wineprefix")
+	if err := b.Prefix.Update(); err != nil {
+		return fmt.Errorf("repair registry: %w", err)
+	}
+
+	if err := b.Prefix.CheckRegistry(); err != nil {
+		return fmt.Errorf("registry is still corrupted: %w", err)
+	}
+
+	slog.Info("Repaired Wineprefix registry")
+	return nil
+}
+
+// CheckWineVersion compares the Wine version against the one the Binary's
+// Wineprefix was last used with, and if they differ, handles the mismatch
+// based on the configuration's wine_mismatch: updating the Wineprefix,
+// only warning, or refusing to continue.
+func (b *Binary) CheckWineVersion() error {
+	v, err := b.Prefix.Version()
+	if err != nil {
+		slog.Warn("Could not determine Wine version, skipping version check", "error", err)
+		return nil
+	}
+	ver := v.String()
+	old := b.State.WineVersion
+	if ver == old {
+		return nil
+	}
+
+	if old != "" {
+		slog.Warn("Wine version has changed", "recorded", old, "current", ver,
+			"behavior", b.GlobalConfig.WineMismatch)
+
+		switch b.GlobalConfig.WineMismatch {
+		case "fail":
+			return fmt.Errorf("%w: %s, recorded %s", ErrWineMismatch, ver, old)
+		case "warn":
+			return nil
+		case "update":
+			b.SetMessage("Updating wineprefix")
+			if err := b.Prefix.Update(); err != nil {
+				return fmt.Errorf("update wineprefix: %w", err)
+			}
+		}
+	}
+
+	b.State.WineVersion = ver
+	return b.GlobalState.Save()
+}
+
+// ErrorDialog shows the given message in a dialog, and waits until it is
+// dismissed or Vinegar receives an interrupt or termination signal, so
+// that an unattended error dialog can be released by other programs.
+func (b *Binary) ErrorDialog(msg string) {
+	done := make(chan struct{})
+	go func() {
+		b.Splash.Dialog(msg, false)
+		close(done)
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
+
+	select {
+	case <-done:
+	case s := <-c:
+		slog.Warn("Recieved signal, closing dialog", "signal", s)
+	}
+}
+
+// InitPrefix initializes the Binary's Wineprefix, retrying up to
+// prefixInitRetries times if Wine had exited unsuccessfully, which
+// is usually transient. Failures to run Wine itself are not retried.
+func (b *Binary) InitPrefix() error {
+	for i := 0; ; i++ {
+		var err error
+		switch b.Type {
+		case roblox.Player:
+			err = b.Prefix.Init()
+		case roblox.Studio:
+			// Studio accepts all DPIs except the default, which is 96.
+			// Technically this is 'initializing wineprefix', as SetDPI calls Wine which
+			// automatically create the Wineprefix.
+			err = b.Prefix.SetDPI(97)
+		}
+
+		var exitErr *exec.ExitError
+		if err == nil || i == prefixInitRetries || !errors.As(err, &exitErr) {
+			return err
+		}
+
+		slog.Warn("Wineprefix initialization failed, retrying...", "error", err, "attempt", i+1)
+		b.SetMessage("Retrying wineprefix initialization")
+
+		// Ensure no leftover processes from the failed attempt interfere
+		_ = b.Prefix.Kill()
+		time.Sleep(time.Second)
+	}
+}
+
+// URIChannel returns the user channel requested by Roblox
+// in the given launch URI, if any.
+func URIChannel(mime string) (channel string) {
 	uris := strings.Split(mime, "+")
 	for _, uri := range uris {
 		kv := strings.Split(uri, ":")
 
-		if len(kv) == 2 && kv[0] == "channel" {
-			c := kv[1]
-			if c == "" {
-				continue
-			}
-
-			slog.Warn("Roblox has requested a user channel, changing...", "channel", c)
-			b.Config.Channel = c
+		if len(kv) == 2 && kv[0] == "channel" && kv[1] != "" {
+			slog.Warn("Roblox has requested a user channel", "channel", kv[1])
+			channel = kv[1]
 		}
 	}
+
+	return
 }
 
 func (b *Binary) Run(args ...string) error {
@@ -219,6 +680,11 @@ func (b *Binary) Run(args ...string) error {
 		}
 	}
 
+	if b.GlobalConfig.ExportActivity {
+		b.Activity.OnUpdate = b.ExportActivity
+		defer os.Remove(b.ActivityPath())
+	}
+
 	// Studio can run in multiple instances, not Player
 	if b.GlobalConfig.MultipleInstances && b.Type == roblox.Player {
 		slog.Info("Running robloxmutexer")
@@ -240,6 +706,9 @@ func (b *Binary) Run(args ...string) error {
 	}
 
 	// Roblox will keep running if it was sent SIGINT; requiring acting as the signal holder.
+	exited := make(chan struct{})
+	launched := make(chan struct{})
+	webView := b.BackgroundWebView(launched, exited)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
@@ -247,20 +716,36 @@ func (b *Binary) Run(args ...string) error {
 
 		slog.Warn("Recieved signal", "signal", s)
 
-		// Only kill Roblox if it hasn't exited
-		if cmd.ProcessState == nil {
-			slog.Warn("Killing Roblox", "pid", cmd.Process.Pid)
-			// This way, cmd.Run() will return and vinegar (should) exit.
-			cmd.Process.Kill()
-		}
-
 		// Don't handle INT after it was recieved, this way if another signal was sent,
 		// Vinegar will immediately exit.
 		signal.Stop(c)
+
+		// Only stop Roblox if it has started and hasn't exited
+		if cmd.Process == nil {
+			return
+		}
+		select {
+		case <-exited:
+			return
+		default:
+		}
+
+		grace := time.Duration(b.GlobalConfig.KillGracePeriod) * time.Second
+		slog.Warn("Terminating Roblox", "pid", cmd.Process.Pid, "grace_period", grace)
+		cmd.Process.Signal(syscall.SIGTERM)
+
+		select {
+		case <-exited:
+		case <-time.After(grace):
+			slog.Warn("Roblox did not exit within grace period, killing Roblox", "pid", cmd.Process.Pid)
+			// This way, cmd.Run() will return and vinegar (should) exit.
+			cmd.Process.Kill()
+		}
 	}()
 
-	slog.Info("Running Binary", "name", b.Name, "cmd", cmd)
-	b.Splash.SetMessage("Launching " + b.Alias)
+	slog.Info("Running Binary", "name", b.Name,
+		"path", cmd.Path, "args", RedactArgs(cmd.Args), "env", EnvDelta(startEnv, cmd.Env))
+	b.SetMessage("Launching " + b.Alias)
 
 	go func() {
 		// Wait for process to start
@@ -272,31 +757,91 @@ func (b *Binary) Run(args ...string) error {
 
 		// If the log file wasn't found, assume failure
 		// and don't perform post-launch roblox functions.
-		lf, err := RobloxLogFile(b.Prefix)
+		lf, err := RobloxLogFile(b.Prefix, b.GlobalConfig.LogDiscovery)
 		if err != nil {
 			slog.Error("Failed to find Roblox log file", "error", err.Error())
+			b.Bench.Report(os.Stdout)
 			return
 		}
 
-		b.Splash.Close()
+		b.Bench.Mark("Process start to log file found")
+		b.Bench.Report(os.Stdout)
+		close(launched)
+
+		go b.WaitWindow(exited)
+		b.Events.Send(events.Event{Type: events.Launch, PID: cmd.Process.Pid})
+
+		// The launched process may be Wine, a launcher or Gamescope.
+		pid, ok := DescendantComm(cmd.Process.Pid, b.Type.Executable())
+		if !ok {
+			pid = cmd.Process.Pid
+		}
 
-		if b.Config.GameMode {
-			b.RegisterGameMode(int32(cmd.Process.Pid))
+		if b.Config.GameMode && b.Config.GameModeScope != "all" {
+			b.RegisterGameMode(int32(pid))
 		}
 
+		b.SetPriority(pid)
+
+		go b.SetWMClass()
+		go b.ActivateWindow()
+		go b.WatchWindow(cmd.Process, exited)
+
 		// Blocks and tails file forever until roblox is dead, unless
 		// if finding the log file had failed.
 		b.Tail(lf)
 	}()
 
-	if err := cmd.Run(); err != nil {
+	// Wine reports missing libraries in it's own output.
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, &lineWriter{fn: b.Diagnose})
+
+	b.Bench.Mark("Launch preparation")
+
+	err = cmd.Run()
+	close(exited)
+
+	if webView != nil {
+		slog.Info("Waiting for WebView installation to finish")
+		<-webView
+	}
+
+	code := cmd.ProcessState.ExitCode()
+	b.Events.Send(events.Event{Type: events.Exit, Code: &code})
+
+	if err != nil {
 		return fmt.Errorf("roblox process: %w", err)
 	}
 
 	return nil
 }
 
-func RobloxLogFile(pfx *wine.Prefix) (string, error) {
+// SetMessage sets the current stage's message on the splash and events stream.
+func (b *Binary) SetMessage(msg string) {
+	if b.Text.Load() != nil {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+
+	b.Splash.SetMessage(msg)
+	b.Events.Send(events.Event{Type: events.Message, Message: msg})
+}
+
+// SetDesc sets the current stage's description on the splash and events stream.
+func (b *Binary) SetDesc(desc string) {
+	b.Splash.SetDesc(desc)
+	b.Events.Send(events.Event{Type: events.Desc, Message: desc})
+}
+
+// SetProgress sets the current stage's progress on the splash and events stream.
+func (b *Binary) SetProgress(progress float32) {
+	b.Splash.SetProgress(progress)
+	b.Events.Send(events.Event{Type: events.Progress, Progress: progress})
+}
+
+// RobloxLogFile waits for Roblox to create a new log file within the
+// Wineprefix and returns it's path. The log directory is watched with
+// fsnotify, or polled if configured to, or if its filesystem is known
+// to not deliver file events reliably.
+func RobloxLogFile(pfx *wine.Prefix, mode string) (string, error) {
 	ad, err := pfx.AppDataDir()
 	if err != nil {
 		return "", fmt.Errorf("get appdata: %w", err)
@@ -310,14 +855,29 @@ func RobloxLogFile(pfx *wine.Prefix) (string, error) {
 		return "", fmt.Errorf("create roblox log dir: %w", err)
 	}
 
+	if mode == "auto" {
+		mode = "fsnotify"
+		fs, err := sysinfo.Filesystem(dir)
+		if err == nil && (slices.Contains(PollFilesystems, fs) || strings.HasPrefix(fs, "fuse.")) {
+			slog.Info("Polling for Roblox log file", "filesystem", fs)
+			mode = "poll"
+		}
+	}
+
+	if mode == "poll" {
+		return pollLogFile(dir)
+	}
+
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
-		return "", fmt.Errorf("make fsnotify watcher: %w", err)
+		slog.Warn("Failed to watch for Roblox log file, polling instead", "error", err)
+		return pollLogFile(dir)
 	}
 	defer w.Close()
 
 	if err := w.Add(dir); err != nil {
-		return "", fmt.Errorf("watch roblox log dir: %w", err)
+		slog.Warn("Failed to watch for Roblox log file, polling instead", "error", err)
+		return pollLogFile(dir)
 	}
 
 	t := time.NewTimer(timeout)
@@ -336,6 +896,41 @@ func RobloxLogFile(pfx *wine.Prefix) (string, error) {
 	}
 }
 
+// pollLogFile periodically lists the named log directory
+// and returns the first file that was not present initially.
+func pollLogFile(dir string) (string, error) {
+	seen := make(map[string]bool)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("list roblox log dir: %w", err)
+	}
+	for _, e := range entries {
+		seen[e.Name()] = true
+	}
+
+	tick := time.NewTicker(logPollInterval)
+	defer tick.Stop()
+	t := time.NewTimer(timeout)
+
+	for {
+		select {
+		case <-t.C:
+			return "", fmt.Errorf("roblox log file not found after %s", timeout)
+		case <-tick.C:
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				return "", fmt.Errorf("list roblox log dir: %w", err)
+			}
+
+			for _, e := range entries {
+				if !seen[e.Name()] && !e.IsDir() {
+					return filepath.Join(dir, e.Name()), nil
+				}
+			}
+		}
+	}
+}
+
 func (b *Binary) Tail(name string) {
 	t, err := tail.TailFile(name, tail.Config{Follow: true})
 	if err != nil {
@@ -344,9 +939,16 @@ func (b *Binary) Tail(name string) {
 	}
 
 	for line := range t.Lines {
-		fmt.Fprintln(b.Prefix.Stderr, line.Text)
+		fmt.Fprintln(b.Prefix.Stdout, line.Text)
+
+		if b.Journal != nil {
+			b.Journal.Send(journal.Info, "roblox", line.Text)
+		}
 
-		if b.Config.DiscordRPC {
+		b.HandleWebViewLog(line.Text)
+		b.Diagnose(line.Text)
+
+		if b.Config.DiscordRPC || b.GlobalConfig.ExportActivity {
 			if err := b.Activity.HandleRobloxLog(line.Text); err != nil {
 				slog.Error("Activity Roblox log handle failed", "error", err)
 			}
@@ -360,9 +962,14 @@ func (b *Binary) Command(args ...string) (*wine.Cmd, error) {
 	}
 
 	cmd := b.Prefix.Wine(filepath.Join(b.Dir, b.Type.Executable()), args...)
+	cmd.Dir = b.Config.WorkDir
 
 	launcher := strings.Fields(b.Config.Launcher)
 	if len(launcher) >= 1 {
+		if err := b.Config.CheckLauncher(); err != nil {
+			return nil, err
+		}
+
 		cmd.Args = append(launcher, cmd.Args...)
 		p, err := b.Config.LauncherPath()
 		if err != nil {
@@ -371,21 +978,120 @@ func (b *Binary) Command(args ...string) (*wine.Cmd, error) {
 		cmd.Path = p
 	}
 
+	if b.Config.Focus == "none" {
+		cmd.Env = slices.DeleteFunc(cmd.Environ(), func(e string) bool {
+			k, _, _ := strings.Cut(e, "=")
+			return slices.Contains(activationEnv, k)
+		})
+	}
+
+	if b.Config.GameMode && b.Config.GameModeScope == "all" {
+		p, err := exec.LookPath("gamemoderun")
+		if err != nil {
+			slog.Warn("gamemoderun not found, GameMode will not be used", "error", err)
+		} else {
+			cmd.Args = append([]string{"gamemoderun"}, cmd.Args...)
+			cmd.Path = p
+		}
+	}
+
+	if b.Config.Gamescope.Enabled {
+		p, err := b.Config.Gamescope.Path()
+		if err != nil {
+			return nil, err
+		}
+
+		gs := append([]string{"gamescope"}, b.Config.Gamescope.Args()...)
+		cmd.Args = append(append(gs, "--"), cmd.Args...)
+		cmd.Path = p
+	}
+
 	return cmd, nil
 }
 
+// SetPriority applies the configured niceness and I/O scheduling
+// to the given process. Failures are only logged, as raising
+// priority commonly requires privileges.
+func (b *Binary) SetPriority(pid int) {
+	if b.Config.Nice != 0 {
+		if err := SetNice(pid, b.Config.Nice); err != nil {
+			slog.Warn("Failed to set Roblox niceness", "nice", b.Config.Nice, "error", err)
+		} else {
+			slog.Info("Set Roblox niceness", "pid", pid, "nice", b.Config.Nice)
+		}
+	}
+
+	if b.Config.IOClass != "" {
+		err := SetIOPriority(pid, ioClasses[b.Config.IOClass], b.Config.IOPriority)
+		if err != nil {
+			slog.Warn("Failed to set Roblox I/O priority",
+				"class", b.Config.IOClass, "priority", b.Config.IOPriority, "error", err)
+		} else {
+			slog.Info("Set Roblox I/O priority",
+				"pid", pid, "class", b.Config.IOClass, "priority", b.Config.IOPriority)
+		}
+	}
+}
+
+// RegisterGameMode registers the given process to GameMode through the
+// GameMode portal. If GameMode is unavailable, such as when it's daemon
+// is not running, only a warning is logged.
 func (b *Binary) RegisterGameMode(pid int32) {
 	conn, err := dbus.ConnectSessionBus()
 	if err != nil {
-		slog.Error("Failed to connect to D-Bus", "error", err)
+		slog.Warn("Failed to connect to D-Bus, not registering to GameMode", "error", err)
 		return
 	}
 
 	desktop := conn.Object("org.freedesktop.portal.Desktop", "/org/freedesktop/portal/desktop")
 
-	call := desktop.Call("org.freedesktop.portal.GameMode.RegisterGame", 0, pid)
-	if call.Err != nil && !errors.Is(call.Err, dbus.ErrMsgNoObject) {
-		slog.Error("Failed to register to GameMode", "error", err)
+	var res int32
+	err = desktop.Call("org.freedesktop.portal.GameMode.RegisterGame", 0, pid).Store(&res)
+	if err != nil || res != 0 {
+		slog.Warn("GameMode is unavailable, is the GameMode daemon running?", "error", err, "result", res)
 		return
 	}
+
+	slog.Info("Registered to GameMode", "pid", pid)
+}
+
+// EnvDelta returns the variables in env that are not present in base
+// or have a different value, with the values of sensitive variables redacted.
+func EnvDelta(base, env []string) (delta []string) {
+	for _, e := range env {
+		if slices.Contains(base, e) {
+			continue
+		}
+
+		if k, _, _ := strings.Cut(e, "="); SensitiveEnv(k) {
+			e = k + "=<redacted>"
+		}
+
+		delta = append(delta, e)
+	}
+
+	return
+}
+
+// RedactArgs returns a copy of args with likely secrets redacted, such
+// as the authentication ticket within a Player launch URI.
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, a := range args {
+		redacted[i] = secretPattern.ReplaceAllString(a, "${1}<redacted>")
+	}
+
+	return redacted
+}
+
+// SensitiveEnv determines if the named environment variable's
+// value should be redacted from logs and reports.
+func SensitiveEnv(name string) bool {
+	for _, s := range sensitiveEnv {
+		if strings.Contains(strings.ToUpper(name), s) {
+			return true
+		}
+	}
+
+	return false
 }
@@ -1,21 +1,44 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
+	"syscall"
+	"time"
 
 	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/netutil"
+	"github.com/vinegarhq/vinegar/internal/state"
 	"github.com/vinegarhq/vinegar/roblox"
 	boot "github.com/vinegarhq/vinegar/roblox/bootstrapper"
+	"github.com/vinegarhq/vinegar/sysinfo"
+	"github.com/vinegarhq/vinegar/wine"
 	"github.com/vinegarhq/vinegar/wine/dxvk"
-	"golang.org/x/sync/errgroup"
+	"github.com/vinegarhq/vinegar/wine/vkd3d"
 )
 
+const (
+	// maxExtractThreads is the most packages extracted at once by default,
+	// as more mostly contend for the disk rather than the CPU.
+	maxExtractThreads = 8
+
+	// hddExtractThreads is the most packages extracted at once
+	// when the installation resides on a hard disk drive.
+	hddExtractThreads = 2
+)
+
+// MinWineVersion is the oldest Wine version known to run Roblox and
+// its WebView2 installer.
+var MinWineVersion = wine.Version{Major: 8, Minor: 0}
+
 func (b *Binary) FetchDeployment() error {
-	b.Splash.SetMessage("Fetching " + b.Alias)
+	b.SetMessage("Fetching " + b.Alias)
 
 	if b.Config.ForcedVersion != "" {
 		slog.Warn("Using forced deployment!", "guid", b.Config.ForcedVersion)
@@ -30,61 +53,278 @@ func (b *Binary) FetchDeployment() error {
 		return fmt.Errorf("fetch %s %s deployment: %w", b.Type, b.Config.Channel, err)
 	}
 
+	if d.GUID == b.State.Skipped && b.State.Deployment != nil {
+		slog.Warn("Not updating to the version rolled back from", "guid", d.GUID, "installed", b.State.Version)
+		d = boot.NewDeployment(b.Type, b.State.Deployment.Channel, b.State.Version)
+	}
+
 	b.Deploy = &d
 	return nil
 }
 
-func (b *Binary) Setup() error {
+// ForcedInstalled determines if the configuration forces a version
+// which is already installed, in which case it is used without
+// being installed, verified or otherwise requiring network access.
+func (b *Binary) ForcedInstalled() bool {
+	if b.Config.ForcedVersion == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(b.Dir, b.Type.Executable()))
+	return err == nil
+}
+
+// RecordInstall records the given package manifest's deployment as the
+// Binary's installed version in the state, keeping the version it replaces
+// as a previous version to roll back to.
+func (b *Binary) RecordInstall(pm *boot.PackageManifest) {
+	if b.State.Version != pm.Deployment.GUID {
+		b.State.Keep(b.GlobalConfig.KeepPrevious)
+		b.State.Previous = slices.DeleteFunc(b.State.Previous, func(d state.Deployment) bool {
+			return d.GUID == pm.Deployment.GUID
+		})
+		b.State.Skipped = ""
+	}
+
+	b.State.Add(pm)
+}
+
+// SetupResult describes what was done by Setup.
+type SetupResult struct {
+	Version    string        // Deployment GUID that was set up
+	Previous   string        // Deployment GUID installed beforehand, if any
+	Updated    bool          // Whether the version was newly installed
+	Downloaded int           // Amount of packages downloaded
+	Bytes      int64         // Size of the packages downloaded
+	Duration   time.Duration // Time taken by Setup
+}
+
+// LogValue implements [slog.LogValuer].
+func (r *SetupResult) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("version", r.Version),
+		slog.String("previous", r.Previous),
+		slog.Bool("updated", r.Updated),
+		slog.Int("downloaded", r.Downloaded),
+		slog.Int64("bytes", r.Bytes),
+		slog.Duration("duration", r.Duration),
+	)
+}
+
+// Setup fetches the Binary's deployment and installs or verifies it,
+// and applies the Binary's FFlags, overlay and Wineprefix modifications,
+// returning a description of what was done.
+func (b *Binary) Setup() (*SetupResult, error) {
+	res := &SetupResult{Previous: b.State.Version}
+	start := time.Now()
+
+	if v, err := b.Prefix.Version(); err == nil && v.Less(MinWineVersion) {
+		slog.Warn("Wine is older than the minimum supported version, WebView and Roblox may not work",
+			"version", v, "minimum", MinWineVersion)
+	}
+
 	if err := b.FetchDeployment(); err != nil {
-		return err
+		return nil, err
 	}
 
 	b.Dir = filepath.Join(dirs.Versions, b.Deploy.GUID)
-	b.Splash.SetDesc(fmt.Sprintf("%s %s", b.Deploy.GUID, b.Deploy.Channel))
+	b.SetDesc(fmt.Sprintf("%s %s", b.Deploy.GUID, b.Deploy.Channel))
+	res.Version = b.Deploy.GUID
 
-	if b.State.Version != b.Deploy.GUID {
+	var downloaded boot.Packages
+	shared, err := b.SetupSharedVersion()
+	if err != nil {
+		return nil, fmt.Errorf("setup shared %s: %w", b.Deploy.GUID, err)
+	}
+
+	if shared {
+		slog.Info("Using shared Binary", "name", b.Name, "guid", b.Deploy.GUID)
+	} else if b.ForcedInstalled() {
+		// Forced versions never change, and are used as-is.
+		slog.Info("Using installed forced version", "name", b.Name, "guid", b.Deploy.GUID)
+		if b.State.Version != b.Deploy.GUID {
+			// Recorded to not be removed as an unused version.
+			b.RecordInstall(&boot.PackageManifest{Deployment: b.Deploy})
+		}
+	} else if b.State.Version != b.Deploy.GUID || b.ChannelChanged() {
 		slog.Info("Installing Binary", "name", b.Name,
 			"old_guid", b.State.Version, "new_guid", b.Deploy.GUID)
 
-		if err := b.Install(); err != nil {
-			return fmt.Errorf("install %s: %w", b.Deploy.GUID, err)
+		downloaded, err = b.Install()
+		if err != nil {
+			return nil, fmt.Errorf("install %s: %w", b.Deploy.GUID, err)
 		}
-	} else {
-		slog.Info("Binary is up to date!", "name", b.Name, "guid", b.Deploy.GUID)
+		res.Updated = true
+	} else if downloaded, err = b.VerifyInstall(); err != nil {
+		return nil, fmt.Errorf("verify %s: %w", b.Deploy.GUID, err)
+	}
+
+	res.Downloaded = len(downloaded)
+	for _, pkg := range downloaded {
+		res.Bytes += pkg.ZipSize
 	}
 
 	b.Config.Env.Setenv()
 
-	if err := b.Config.FFlags.Apply(b.Dir); err != nil {
-		return fmt.Errorf("apply fflags: %w", err)
+	if err := b.ApplyFFlags(); err != nil {
+		return nil, fmt.Errorf("apply fflags: %w", err)
 	}
 
 	if err := dirs.OverlayDir(b.Dir); err != nil {
-		return fmt.Errorf("overlay dir: %w", err)
+		return nil, fmt.Errorf("overlay dir: %w", err)
+	}
+
+	if b.Config.NoUpdater {
+		if err := b.DisableUpdater(); err != nil {
+			return nil, fmt.Errorf("disable updater: %w", err)
+		}
 	}
 
 	if err := b.SetupDxvk(); err != nil {
-		return fmt.Errorf("setup dxvk: %w", err)
+		return nil, fmt.Errorf("setup dxvk: %w", err)
+	}
+
+	if err := b.SetupVkd3d(); err != nil {
+		return nil, fmt.Errorf("setup vkd3d: %w", err)
 	}
 
-	b.Splash.SetProgress(1.0)
+	b.SetProgress(1.0)
 	if err := b.GlobalState.Save(); err != nil {
-		return fmt.Errorf("save state: %w", err)
+		return nil, fmt.Errorf("save state: %w", err)
 	}
 
+	if res.Updated {
+		if err := b.RunHook("postupdate", &b.Config.PostUpdate); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+// SettingsFile returns the path to the Binary's FFlags settings file,
+// which is the configuration's settings_file if set, relative to the
+// Binary's version directory, otherwise the detected settings file.
+func (b *Binary) SettingsFile() string {
+	p := b.Config.SettingsFile
+	if p == "" {
+		return roblox.SettingsFile(b.Type, b.Dir)
+	}
+
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(b.Dir, p)
+	}
+
+	return p
+}
+
+// ApplyFFlags merges the Binary's FFlags, including those of the
+// experience being joined, into it's settings file. FFlags applied
+// previously that are no longer configured are removed, while those
+// added to the file by other means are kept.
+func (b *Binary) ApplyFFlags() error {
+	path := b.SettingsFile()
+	ff := b.Config.FFlagsFor(b.Place)
+	names := make([]string, 0, len(ff))
+	for name := range ff {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	slog.Info("Applying FFlags", "path", path, "place", b.Place, "fflags", names)
+
+	if err := ff.Merge(path, b.State.FFlags...); err != nil {
+		return err
+	}
+
+	b.State.FFlags = names
 	return nil
 }
 
-func (b *Binary) Install() error {
-	b.Splash.SetMessage("Installing " + b.Alias)
+// DisableUpdater prevents Roblox from updating itself in-place, which
+// would otherwise replace the installation Vinegar manages without
+// recording it in the state. The Binary's updater executable is removed,
+// and the installed version is recorded in the registry as Roblox's own
+// bootstrapper would. Roblox may instead prompt the user to update, which
+// will happen once Vinegar installs the new version on the next launch.
+func (b *Binary) DisableUpdater() error {
+	updater := filepath.Join(b.Dir, b.Type.Updater())
+
+	// The updater is only present after an installation.
+	err := os.Remove(updater)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	slog.Info("Removed Roblox updater", "path", updater)
+
+	return b.Prefix.RegistryAdd(
+		`HKEY_CURRENT_USER\Software\ROBLOX Corporation\Environments\`+b.Type.RegistryEnvironment(),
+		"version", wine.REG_SZ, b.Deploy.GUID)
+}
+
+// ChannelChanged determines if the installed version should be reinstalled
+// due to the deployment's channel having changed, which is only the case
+// if the configuration's channel_change is 'reinstall'.
+//
+// A different version on the new channel is always installed.
+func (b *Binary) ChannelChanged() bool {
+	d := b.State.Deployment
+	if d == nil || d.Channel == b.Deploy.Channel {
+		return false
+	}
+
+	reinstall := b.GlobalConfig.ChannelChange == "reinstall"
+	slog.Info("Deployment channel has changed", "name", b.Name,
+		"old_channel", d.Channel, "new_channel", b.Deploy.Channel,
+		"policy", b.GlobalConfig.ChannelChange, "reinstall", reinstall)
+
+	if !reinstall {
+		d.Channel = b.Deploy.Channel
+	}
+
+	return reinstall
+}
+
+// VerifyInstall checks the Binary's installation. Every verify_interval
+// launches, the installation is fully verified by installing it again,
+// which verifies the cached packages and extracts them; otherwise, only
+// the existence of the Binary's executable is checked.
+func (b *Binary) VerifyInstall() (boot.Packages, error) {
+	b.State.Launches++
+
+	n := b.GlobalConfig.VerifyInterval
+	if n == 0 || b.State.Launches < n {
+		if _, err := os.Stat(filepath.Join(b.Dir, b.Type.Executable())); err == nil {
+			slog.Info("Binary is up to date!", "name", b.Name, "guid", b.Deploy.GUID)
+			return nil, nil
+		}
+
+		slog.Warn("Binary executable is missing, reinstalling", "name", b.Name, "dir", b.Dir)
+	} else {
+		slog.Info("Verifying Binary installation", "name", b.Name, "launches", b.State.Launches)
+	}
+
+	return b.Install()
+}
+
+// Install installs the Binary's deployment, returning
+// the packages that had to be downloaded.
+func (b *Binary) Install() (boot.Packages, error) {
+	b.SetMessage("Installing " + b.Alias)
 
 	if err := dirs.Mkdirs(dirs.Downloads); err != nil {
-		return err
+		return nil, err
+	}
+
+	if _, err := boot.CleanStale(dirs.Downloads); err != nil {
+		slog.Warn("Could not clean leftover downloads", "error", err)
 	}
 
 	pm, err := boot.FetchPackageManifest(b.Deploy)
 	if err != nil {
-		return fmt.Errorf("fetch %s package manifest: %w", b.Deploy.GUID, err)
+		return nil, fmt.Errorf("fetch %s package manifest: %w", b.Deploy.GUID, err)
 	}
 
 	// Prioritize smaller files first, to have less pressure
@@ -95,93 +335,107 @@ func (b *Binary) Install() error {
 		return pm.Packages[i].ZipSize < pm.Packages[j].ZipSize
 	})
 
-	b.Splash.SetMessage("Downloading " + b.Alias)
-	if err := b.DownloadPackages(&pm); err != nil {
-		return fmt.Errorf("download %s packages: %w", b.Deploy.GUID, err)
+	// The packages are extracted to a staging directory which replaces
+	// the version directory only once it has been fully set up, so that
+	// an interrupted installation never leaves a broken version behind.
+	staging := b.Dir + ".staging"
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, fmt.Errorf("remove stale staging: %w", err)
 	}
+	defer os.RemoveAll(staging)
+
+	delta := b.ReusePackages(&pm, staging)
+
+	delta.DownloadThreads = b.GlobalConfig.DownloadThreads
+	stop := b.HandlePauseSignals()
+	downloaded, err := delta.Download(dirs.Downloads, b)
+	stop()
+	if err != nil {
+		return nil, fmt.Errorf("download %s packages: %w", b.Deploy.GUID, err)
+	}
+
+	delta.ExtractThreads = b.ExtractThreads()
+	slog.Info("Using extraction threads", "threads", delta.ExtractThreads)
 
-	b.Splash.SetMessage("Extracting " + b.Alias)
-	if err := b.ExtractPackages(&pm); err != nil {
-		return fmt.Errorf("extract %s packages: %w", b.Deploy.GUID, err)
+	if err := delta.Extract(dirs.Downloads, staging, b); err != nil {
+		return nil, fmt.Errorf("extract %s packages: %w", b.Deploy.GUID, err)
 	}
 
 	if b.Type == roblox.Studio {
-		brokenFont := filepath.Join(b.Dir, "StudioFonts", "SourceSansPro-Black.ttf")
+		brokenFont := filepath.Join(staging, "StudioFonts", "SourceSansPro-Black.ttf")
 
 		slog.Info("Removing broken font", "path", brokenFont)
 		if err := os.RemoveAll(brokenFont); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	if err := boot.WriteAppSettings(b.Dir); err != nil {
-		return fmt.Errorf("appsettings: %w", err)
+	if err := boot.WriteAppSettings(staging); err != nil {
+		return nil, fmt.Errorf("appsettings: %w", err)
 	}
 
-	b.State.Add(&pm)
+	if err := swapDir(staging, b.Dir); err != nil {
+		return nil, fmt.Errorf("move staging into place: %w", err)
+	}
+
+	b.RecordInstall(&pm)
 
 	if err := b.GlobalState.CleanPackages(); err != nil {
-		return fmt.Errorf("clean packages: %w", err)
+		return nil, fmt.Errorf("clean packages: %w", err)
 	}
 
 	if err := b.GlobalState.CleanVersions(); err != nil {
-		return fmt.Errorf("clean versions: %w", err)
+		return nil, fmt.Errorf("clean versions: %w", err)
 	}
 
-	return nil
+	return downloaded, nil
 }
 
-func (b *Binary) PerformPackages(pm *boot.PackageManifest, fn func(boot.Package) error) error {
-	donePkgs := 0
-	pkgsLen := len(pm.Packages)
-	eg := new(errgroup.Group)
-
-	for _, p := range pm.Packages {
-		p := p
-		eg.Go(func() error {
-			err := fn(p)
-			if err != nil {
-				return err
-			}
-
-			donePkgs++
-			b.Splash.SetProgress(float32(donePkgs) / float32(pkgsLen))
-
-			return nil
-		})
+// ReusePackages reuses the packages of the given manifest that are
+// unchanged from the Binary's currently installed version in the named
+// staging directory, returning the manifest of the packages that must
+// still be installed. If the installed version differs in an unknown
+// way, or is the same version, no packages are reused.
+func (b *Binary) ReusePackages(pm *boot.PackageManifest, staging string) boot.PackageManifest {
+	old := filepath.Join(dirs.Versions, b.State.Version)
+	installed := b.State.Installed()
+
+	if b.State.Version == "" || old == b.Dir || installed == nil {
+		return *pm
 	}
 
-	return eg.Wait()
-}
-
-func (b *Binary) DownloadPackages(pm *boot.PackageManifest) error {
-	slog.Info("Downloading Packages", "guid", pm.Deployment.GUID, "count", len(pm.Packages))
+	if _, err := os.Stat(old); err != nil {
+		return *pm
+	}
 
-	return b.PerformPackages(pm, func(pkg boot.Package) error {
-		return pkg.Download(filepath.Join(dirs.Downloads, pkg.Checksum), pm.DeployURL)
-	})
+	b.SetMessage("Reusing " + b.Alias + " packages")
+	return pm.Reuse(installed, dirs.Downloads, old, staging)
 }
 
-func (b *Binary) ExtractPackages(pm *boot.PackageManifest) error {
-	slog.Info("Extracting Packages", "guid", pm.Deployment.GUID, "count", len(pm.Packages))
-
-	pkgDirs := boot.BinaryDirectories(b.Type)
+// swapDir renames the src directory to dst, replacing dst if it exists.
+// If dst cannot be replaced, it is left untouched.
+func swapDir(src, dst string) error {
+	old := dst + ".old"
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
 
-	return b.PerformPackages(pm, func(pkg boot.Package) error {
-		dest, ok := pkgDirs[pkg.Name]
+	if err := os.Rename(dst, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
 
-		if !ok {
-			return fmt.Errorf("unhandled package: %s", pkg.Name)
-		}
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return err
+	}
 
-		return pkg.Extract(filepath.Join(dirs.Downloads, pkg.Checksum), filepath.Join(b.Dir, dest))
-	})
+	return os.RemoveAll(old)
 }
 
 func (b *Binary) SetupDxvk() error {
 	if b.State.DxvkVersion != "" &&
 		(!b.GlobalConfig.Player.Dxvk && !b.GlobalConfig.Studio.Dxvk) {
-		b.Splash.SetMessage("Uninstalling DXVK")
+		b.SetMessage("Uninstalling DXVK")
 		if err := dxvk.Remove(b.Prefix); err != nil {
 			return fmt.Errorf("remove dxvk: %w", err)
 		}
@@ -194,16 +448,125 @@ func (b *Binary) SetupDxvk() error {
 		return nil
 	}
 
-	b.Splash.SetProgress(0.0)
+	b.SetProgress(0.0)
 	dxvk.Setenv()
 
-	if b.Config.DxvkVersion == b.State.DxvkVersion {
+	ver := b.Config.DxvkVersion
+	if b.Config.DxvkPath != "" {
+		ver = b.Config.DxvkPath
+	}
+
+	if ver == b.State.DxvkVersion {
 		return nil
 	}
 
-	// This would only get saved if Install succeeded
-	b.State.DxvkVersion = b.Config.DxvkVersion
+	b.SetMessage("Installing DXVK")
+	_, err := b.InstallDxvk()
+	return err
+}
 
-	b.Splash.SetMessage("Installing DXVK")
-	return dxvk.Install(b.Config.DxvkVersion, b.Prefix)
+// InstallDxvk installs the configuration's DXVK version, or DXVK from
+// the configuration's dxvk_path if set, and records it in the Binary's
+// state, returning the version or path installed.
+func (b *Binary) InstallDxvk() (string, error) {
+	ver := b.Config.DxvkVersion
+	var err error
+	if b.Config.DxvkPath != "" {
+		ver = b.Config.DxvkPath
+		err = dxvk.InstallFrom(b.Config.DxvkPath, b.Prefix)
+	} else {
+		err = dxvk.Install(b.Config.DxvkVersion, b.Prefix)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	b.State.DxvkVersion = ver
+	return ver, nil
+}
+
+// SetupVkd3d installs VKD3D-Proton from the configuration's vkd3d_path
+// if it has changed since it was last installed, or removes it if
+// it is no longer configured.
+func (b *Binary) SetupVkd3d() error {
+	if b.Config.Vkd3dPath == "" {
+		if b.State.Vkd3d == "" {
+			return nil
+		}
+
+		b.SetMessage("Uninstalling VKD3D-Proton")
+		if err := vkd3d.Remove(b.Prefix); err != nil {
+			return fmt.Errorf("remove vkd3d: %w", err)
+		}
+
+		b.State.Vkd3d = ""
+		return nil
+	}
+
+	vkd3d.Setenv()
+
+	if b.Config.Vkd3dPath == b.State.Vkd3d {
+		return nil
+	}
+
+	b.SetMessage("Installing VKD3D-Proton")
+	if err := vkd3d.Install(b.Config.Vkd3dPath, b.Prefix); err != nil {
+		return err
+	}
+
+	b.State.Vkd3d = b.Config.Vkd3dPath
+	return nil
+}
+
+// ExtractThreads returns the amount of packages to extract at once,
+// which is the configuration's extract_threads if set, otherwise the
+// amount of logical processors up to maxExtractThreads. It is capped
+// to hddExtractThreads if the installation resides on a hard disk drive,
+// where concurrent extraction causes thrashing.
+func (b *Binary) ExtractThreads() int {
+	n := b.GlobalConfig.ExtractThreads
+	if n == 0 {
+		n = min(sysinfo.CPU.Threads, maxExtractThreads)
+	}
+
+	if sysinfo.Rotational(dirs.Data) {
+		n = min(n, hddExtractThreads)
+	}
+
+	return max(n, 1)
+}
+
+// HandlePauseSignals pauses downloads when SIGUSR1 is received and
+// resumes them when SIGUSR2 is received, reporting the paused state on
+// the splash. The returned function stops handling the signals, and
+// resumes downloads if they were left paused.
+func (b *Binary) HandlePauseSignals() func() {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case s := <-c:
+				if s == syscall.SIGUSR1 && !netutil.Paused() {
+					slog.Info("Pausing download")
+					netutil.Pause()
+					b.SetMessage("Paused downloading " + b.Alias)
+				} else if s == syscall.SIGUSR2 && netutil.Paused() {
+					slog.Info("Resuming download")
+					netutil.Resume()
+					b.SetMessage("Downloading " + b.Alias)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+		netutil.Resume()
+	}
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vinegarhq/vinegar/config"
+)
+
+// ImportBootstrapper translates the named Bloxstrap or Sober configuration
+// file to Vinegar's Player configuration, printed to be added to the
+// configuration file, and warns about settings that could not be translated.
+func ImportBootstrapper(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	imp, err := config.ImportBootstrapper(f)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", name, err)
+	}
+
+	for _, k := range imp.Unmapped {
+		slog.Warn("Setting has no equivalent in Vinegar, skipping", "name", k)
+	}
+
+	slog.Info("Imported configuration, add it to your configuration file",
+		"fflags", len(imp.FFlags), "path", ConfigPath)
+
+	return toml.NewEncoder(os.Stdout).Encode(map[string]*config.Import{
+		"player": imp,
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/vinegarhq/vinegar/sysinfo"
+)
+
+// LoginURL is the Roblox login page opened in the user's browser when
+// the WebView is found to be broken.
+const LoginURL = "https://www.roblox.com/login"
+
+// webViewInitCalls is a list of the WebView2 initialization calls,
+// which Roblox logs the failure of when the WebView is broken.
+var webViewInitCalls = []string{
+	"createcorewebview2environment",
+	"createcorewebview2controller",
+}
+
+// HandleWebViewLog checks the given Roblox log line for a WebView2
+// initialization failure, and marks the WebView as broken once. If
+// browser_login is enabled, the user is notified and the Roblox login
+// page will also be opened in the user's browser.
+func (b *Binary) HandleWebViewLog(line string) {
+	if b.WebViewBroken {
+		return
+	}
+
+	l := strings.ToLower(line)
+	if !strings.Contains(l, "fail") || !slices.ContainsFunc(webViewInitCalls, func(c string) bool {
+		return strings.Contains(l, c)
+	}) {
+		return
+	}
+
+	b.WebViewBroken = true
+	slog.Warn("WebView failed to initialize", "line", line)
+
+	if !b.GlobalConfig.BrowserLogin {
+		return
+	}
+
+	go b.Splash.Dialog(DialogUseBrowser, false)
+
+	slog.Info("Opening Roblox login page in browser", "url", LoginURL)
+	if err := OpenURL(LoginURL); err != nil {
+		slog.Error("Failed to open browser", "error", err)
+	}
+}
+
+// OpenURL opens the named url in the user's default browser, using
+// the OpenURI portal when running in a Flatpak, otherwise xdg-open.
+func OpenURL(url string) error {
+	if !sysinfo.InFlatpak {
+		return exec.Command("xdg-open", url).Start()
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	desktop := conn.Object("org.freedesktop.portal.Desktop", "/org/freedesktop/portal/desktop")
+	return desktop.Call("org.freedesktop.portal.OpenURI.OpenURI", 0,
+		"", url, map[string]dbus.Variant{}).Err
+}
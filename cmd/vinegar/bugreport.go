@@ -0,0 +1,171 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/state"
+)
+
+// secretPattern matches values of key-value pairs that are likely
+// to contain secrets, such as authentication tickets and cookies. This
+// includes the 'gameinfo' authentication ticket of a Player launch URI.
+var secretPattern = regexp.MustCompile(`(?i)((?:gameinfo|\w*token|\w*ticket|\w*cookie|\w*secret|\w*passw\w*|\w*key|auth\w*|\.ROBLOSECURITY)["']?\s*[=:]\s*["']?)[^\s"'&;,+]+`)
+
+// Redact removes the user's home directory and likely secrets from s.
+func Redact(s string) string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+
+	return secretPattern.ReplaceAllString(s, "${1}<redacted>")
+}
+
+// LatestLog returns the path to the most recently modified log
+// file, excluding the separate logs of Wine's output.
+func LatestLog() (string, error) {
+	logs, err := os.ReadDir(dirs.Logs)
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestInfo os.FileInfo
+	for _, l := range logs {
+		fi, err := l.Info()
+		if err != nil || !fi.Mode().IsRegular() || strings.HasPrefix(l.Name(), WineLogPrefix) {
+			continue
+		}
+
+		if latestInfo == nil || fi.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = l.Name(), fi
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no log files in %s", dirs.Logs)
+	}
+
+	return filepath.Join(dirs.Logs, latest), nil
+}
+
+// BugReport writes a zip archive to the named file containing the system
+// information, the most recent log file, the configuration and the state,
+// all with the user's home directory and likely secrets redacted.
+func BugReport(name string, cfg *config.Config) error {
+	files := make(map[string]string)
+
+	var si bytes.Buffer
+	if err := Sysinfo(&si, cfg); err != nil {
+		return err
+	}
+	files["sysinfo.txt"] = si.String()
+
+	var cb bytes.Buffer
+	redacted := *cfg
+	redacted.Env = RedactEnv(cfg.Env)
+	redacted.Player.Env = RedactEnv(cfg.Player.Env)
+	redacted.Studio.Env = RedactEnv(cfg.Studio.Env)
+	redacted.EnvProfiles = redactEnvs(cfg.EnvProfiles)
+	redacted.SessionEnv = redactEnvs(cfg.SessionEnv)
+	if err := toml.NewEncoder(&cb).Encode(redacted); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	files["config.toml"] = cb.String()
+
+	s, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	sb, err := json.MarshalIndent(s, "", " ")
+	if err != nil {
+		return err
+	}
+	files["state.json"] = string(sb)
+
+	if lp, err := LatestLog(); err == nil {
+		l, err := os.ReadFile(lp)
+		if err != nil {
+			return err
+		}
+		files[filepath.Base(lp)] = string(l)
+	} else {
+		slog.Warn("Skipping log file in bug report", "error", err)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for n, c := range files {
+		w, err := zw.Create(n)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, Redact(c)); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("Wrote bug report", "path", name)
+	return nil
+}
+
+// RedactEnv returns a copy of the given environment with the values
+// of sensitive variables redacted.
+func RedactEnv(env config.Environment) config.Environment {
+	r := make(config.Environment, len(env))
+	for k, v := range env {
+		if SensitiveEnv(k) {
+			v = "<redacted>"
+		}
+		r[k] = v
+	}
+
+	return r
+}
+
+func redactEnvs(envs map[string]config.Environment) map[string]config.Environment {
+	r := make(map[string]config.Environment, len(envs))
+	for name, env := range envs {
+		r[name] = RedactEnv(env)
+	}
+
+	return r
+}
+
+// SysinfoCommand parses the sysinfo subcommand's arguments, printing
+// the system information or writing a bug report.
+func SysinfoCommand(cfg *config.Config, args ...string) error {
+	flags := flag.NewFlagSet("sysinfo", flag.ContinueOnError)
+	out := flags.String("o", "", "write a bug report zip archive to the named file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return PrintSysinfo(cfg)
+	}
+
+	return BugReport(*out, cfg)
+}
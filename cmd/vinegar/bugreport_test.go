@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	t.Setenv("HOME", "/home/meow")
+
+	tests := map[string]string{
+		"roblox-player:1+launchmode:play+gameinfo:TICKET123+launchtime:1700000000": "roblox-player:1+launchmode:play+gameinfo:<redacted>+launchtime:1700000000",
+		".ROBLOSECURITY=_|WARNING:-DO-NOT-SHARE-THIS.--COOKIE; path=/":             ".ROBLOSECURITY=<redacted>; path=/",
+		`API_KEY = "hunter2"`:                      `API_KEY = "<redacted>"`,
+		"/home/meow/.local/share/vinegar/prefixes": "~/.local/share/vinegar/prefixes",
+	}
+
+	for in, want := range tests {
+		got := Redact(in)
+		if got != want {
+			t.Errorf("redact %q: got %q, want %q", in, got, want)
+		}
+	}
+
+	if got := Redact("gameinfo:TICKET123"); strings.Contains(got, "TICKET123") {
+		t.Fatalf("ticket not redacted: %q", got)
+	}
+}
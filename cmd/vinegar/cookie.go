@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// studioCookieKey is the registry key holding the cookies
+// of Roblox Studio's login session.
+const studioCookieKey = `HKEY_CURRENT_USER\Software\Roblox\RobloxStudioBrowser\roblox.com`
+
+// CookieKeyringAttributes are the attributes of the keyring secret
+// holding the .ROBLOSECURITY cookie, as stored with:
+//
+//	secret-tool store --label='Roblox Studio' application vinegar cookie roblosecurity
+var CookieKeyringAttributes = []string{"application", "vinegar", "cookie", "roblosecurity"}
+
+var (
+	ErrCookiePermissions = errors.New("cookie file must only be accessible by its owner")
+	ErrNoCookie          = errors.New("no cookie found")
+)
+
+// Cookie returns the .ROBLOSECURITY cookie configured for the Binary,
+// read from the configured file or the keyring, or an empty string if
+// neither is configured. The file must not be accessible by others.
+func (b *Binary) Cookie() (string, error) {
+	var c []byte
+
+	switch {
+	case b.Config.CookieFile != "":
+		fi, err := os.Stat(b.Config.CookieFile)
+		if err != nil {
+			return "", err
+		}
+
+		if fi.Mode().Perm()&0o077 != 0 {
+			return "", fmt.Errorf("%w: %s is %s, run 'chmod 600 %[2]s'",
+				ErrCookiePermissions, b.Config.CookieFile, fi.Mode().Perm())
+		}
+
+		c, err = os.ReadFile(b.Config.CookieFile)
+		if err != nil {
+			return "", err
+		}
+	case b.Config.CookieKeyring:
+		var err error
+		cmd := exec.Command("secret-tool", append([]string{"lookup"}, CookieKeyringAttributes...)...)
+		c, err = cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("keyring: %w", err)
+		}
+	default:
+		return "", nil
+	}
+
+	cookie := string(bytes.TrimSpace(c))
+	if cookie == "" {
+		return "", ErrNoCookie
+	}
+
+	return cookie, nil
+}
+
+// InjectCookie logs Roblox Studio in with the configured .ROBLOSECURITY
+// cookie, by importing it into the Wineprefix's registry where Studio
+// stores it's login session. The cookie is never passed in a process'
+// arguments, and the intermediate registry file is removed afterwards.
+func (b *Binary) InjectCookie() error {
+	cookie, err := b.Cookie()
+	if err != nil || cookie == "" {
+		return err
+	}
+
+	slog.Warn("Injecting .ROBLOSECURITY cookie into the Studio wineprefix! " +
+		"Anyone with access to the cookie or the wineprefix can fully control the account, " +
+		"only use a dedicated account and clear it with 'vinegar studio clear-cookie' when done")
+
+	dir := filepath.Join(b.Prefix.Dir(), "drive_c", "windows", "temp")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, "vinegar-cookie-*.reg")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	_, err = fmt.Fprintf(f, "REGEDIT4\n\n[%s]\n\".ROBLOSECURITY\"=\"SEC::<YES>,EXP::<9999-01-01T00:00:00Z>,COOK::<%s>\"\n",
+		studioCookieKey, r.Replace(cookie))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := b.Prefix.RegistryImport(f.Name()); err != nil {
+		return fmt.Errorf("import cookie: %w", err)
+	}
+
+	slog.Info("Injected .ROBLOSECURITY cookie")
+	return nil
+}
+
+// ClearCookie removes the .ROBLOSECURITY cookie from the Wineprefix's
+// registry, logging Roblox Studio out.
+func (b *Binary) ClearCookie() error {
+	slog.Info("Clearing .ROBLOSECURITY cookie from the Studio wineprefix")
+
+	return b.Prefix.RegistryDelete(studioCookieKey, ".ROBLOSECURITY")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"sync"
+)
+
+// Diagnosis is a known failure, detected by a line of Roblox's or
+// Wine's output matching Pattern, with guidance on how to resolve it.
+type Diagnosis struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Guidance string
+}
+
+// Diagnoses is the list of failures checked for by Diagnose.
+var Diagnoses = []Diagnosis{
+	{
+		Name:    "d3d11",
+		Pattern: regexp.MustCompile(`(?i)(d3d11|dxgi)(\.dll)?\b.*(not found|fail|unable)`),
+		Guidance: "Direct3D 11 is unavailable in the Wineprefix, DXVK may be missing or broken. " +
+			"Enable 'dxvk' in the configuration and run 'vinegar player repair', " +
+			"or install DXVK with 'vinegar player winetricks'.",
+	},
+	{
+		Name:    "vulkan",
+		Pattern: regexp.MustCompile(`(?i)VK_ERROR_INCOMPATIBLE_DRIVER|vkCreateInstance.*fail`),
+		Guidance: "No working Vulkan driver was found, which DXVK and the Vulkan renderer require. " +
+			"Install the Vulkan driver for your graphics card, and its 32-bit variant if available.",
+	},
+}
+
+// Diagnose checks the given line of Roblox's or Wine's output against
+// the Diagnoses, and shows the guidance of a matching diagnosis once.
+func (b *Binary) Diagnose(line string) {
+	for _, d := range Diagnoses {
+		if !d.Pattern.MatchString(line) {
+			continue
+		}
+
+		b.diagnosedMu.Lock()
+		seen := b.diagnosed[d.Name]
+		if b.diagnosed == nil {
+			b.diagnosed = make(map[string]bool)
+		}
+		b.diagnosed[d.Name] = true
+		b.diagnosedMu.Unlock()
+
+		if seen {
+			continue
+		}
+
+		slog.Warn("Detected a known failure", "name", d.Name, "line", line, "guidance", d.Guidance)
+		go b.Splash.Dialog(d.Guidance, false)
+	}
+}
+
+// lineWriter is an io.Writer that calls fn with every
+// complete line written to it, without the newline.
+type lineWriter struct {
+	mu  sync.Mutex
+	buf []byte
+	fn  func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.fn(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestDiagnoses(t *testing.T) {
+	for line, want := range map[string]string{
+		`0024:err:module:import_dll Library d3d11.dll (which is needed by L"C:\\RobloxPlayerBeta.exe") not found`: "d3d11",
+		"err:vulkan:wine_vk_instance_load_physical_devices vkCreateInstance failed":                               "vulkan",
+		"2024-01-01T00:00:00.000Z,0.0,1,6 [FLog::Output] Settings Date header was Mon, 01 Jan 2024":               "",
+	} {
+		got := ""
+		for _, d := range Diagnoses {
+			if d.Pattern.MatchString(line) {
+				got = d.Name
+			}
+		}
+
+		if got != want {
+			t.Errorf("diagnosis of %q = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{fn: func(l string) { lines = append(lines, l) }}
+
+	fmt.Fprint(w, "meow\nmr")
+	fmt.Fprint(w, "rp\npartial")
+
+	if !slices.Equal(lines, []string{"meow", "mrrp"}) {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
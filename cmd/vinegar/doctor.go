@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/roblox"
+	"github.com/vinegarhq/vinegar/sysinfo"
+	"github.com/vinegarhq/vinegar/wine"
+	"golang.org/x/sys/unix"
+)
+
+// UnsupportedFilesystems is a list of filesystem types known to cause
+// issues with Wine when a Wineprefix resides on them.
+var UnsupportedFilesystems = []string{
+	"fuseblk", "ntfs", "ntfs3", // NTFS-3G and kernel NTFS
+	"vfat", "msdos", "exfat",
+	"nfs", "nfs4", "cifs", "smb3", "9p",
+}
+
+// NofileSafe is the open file descriptor limit known to be enough for
+// Wine and Roblox, which is also what Wine's esync requires.
+const NofileSafe = 524288
+
+var (
+	ErrUnsupportedFilesystem = errors.New("filesystem is known to be problematic with wine")
+	ErrPrefixOwner           = errors.New("wineprefix is not owned by the current user")
+	ErrLowNofile             = errors.New("open file limit is too low for wine")
+)
+
+// DoctorCheck is a named check of the system or Vinegar's installation,
+// returning a description of the result or an error if it failed.
+type DoctorCheck struct {
+	Name string
+	Run  func(*config.Config) (string, error)
+}
+
+// DoctorChecks is the list of checks ran by Doctor.
+var DoctorChecks = []DoctorCheck{
+	{"Wineprefix filesystems", checkFilesystems},
+	{"Wineprefix ownership", checkOwnership},
+	{"Wineprefix registry", checkRegistry},
+	{"Gamepad access", checkGamepads},
+	{"Open file limit", checkNofile},
+	{"Graphics", checkGPU},
+	{"Graphics compatibility", checkGPUIssues},
+	{"Splash logo", checkSplashLogo},
+}
+
+// Doctor runs all of the DoctorChecks and prints their results,
+// returning an error if any of them have failed.
+func Doctor(cfg *config.Config) error {
+	failed := 0
+
+	for _, c := range DoctorChecks {
+		res, err := c.Run(cfg)
+		if err != nil {
+			fmt.Printf("* %s: [FAIL] %s\n", c.Name, err)
+			failed++
+			continue
+		}
+
+		fmt.Printf("* %s: [OK] %s\n", c.Name, res)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+
+	return nil
+}
+
+// CheckFilesystem returns the type of the filesystem of the named
+// directory, returning [ErrUnsupportedFilesystem] if it is known to be
+// problematic for use by Wine.
+func CheckFilesystem(dir string) (string, error) {
+	fs, err := sysinfo.Filesystem(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if slices.Contains(UnsupportedFilesystems, fs) {
+		return fs, fmt.Errorf("%w: %s", ErrUnsupportedFilesystem, fs)
+	}
+
+	return fs, nil
+}
+
+// CheckPrefixFilesystem checks the Binary's Wineprefix filesystem with
+// CheckFilesystem, only warning about an unsupported filesystem if it
+// has been allowed by the configuration.
+func (b *Binary) CheckPrefixFilesystem() error {
+	fs, err := CheckFilesystem(b.Prefix.Dir())
+	if errors.Is(err, ErrUnsupportedFilesystem) && b.GlobalConfig.AllowUnsupportedFS {
+		slog.Warn("Wineprefix is on an unsupported filesystem, expect issues!", "filesystem", fs)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	slog.Info("Wineprefix filesystem", "filesystem", fs)
+	return nil
+}
+
+// CheckOwnership checks that the named Wineprefix directory and the
+// files Wine writes to first are owned and writable by the current user,
+// which is commonly not the case after Vinegar was run with sudo. Wine
+// refuses to use a Wineprefix owned by another user.
+func CheckOwnership(dir string) error {
+	uid := os.Getuid()
+
+	for _, p := range []string{
+		dir,
+		filepath.Join(dir, "drive_c"),
+		filepath.Join(dir, "system.reg"),
+		filepath.Join(dir, "user.reg"),
+	} {
+		var st unix.Stat_t
+		if err := unix.Stat(p, &st); errors.Is(err, unix.ENOENT) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if int(st.Uid) != uid {
+			return fmt.Errorf("%w: %s is owned by uid %d, fix it with 'sudo chown -R %d: %s'",
+				ErrPrefixOwner, p, st.Uid, uid, dir)
+		}
+
+		if unix.Access(p, unix.W_OK) != nil {
+			return fmt.Errorf("%w: %s is not writable, fix it with 'chmod -R u+w %s'",
+				ErrPrefixOwner, p, dir)
+		}
+	}
+
+	return nil
+}
+
+// RaiseNofile raises the soft open file descriptor limit up to the hard
+// limit, which is inherited by Wine and Roblox, returning [ErrLowNofile]
+// if the hard limit is below [NofileSafe], as they may then fail with
+// EMFILE (too many open files).
+func RaiseNofile() (uint64, error) {
+	var rl unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, err
+	}
+
+	if rl.Cur < rl.Max {
+		raised := rl
+		raised.Cur = rl.Max
+		if err := unix.Setrlimit(unix.RLIMIT_NOFILE, &raised); err != nil {
+			return rl.Cur, fmt.Errorf("raise open file limit: %w", err)
+		}
+		rl = raised
+	}
+
+	return rl.Cur, checkNofileLimit(rl.Cur)
+}
+
+// checkNofileLimit returns [ErrLowNofile] if the given
+// open file descriptor limit is below [NofileSafe].
+func checkNofileLimit(n uint64) error {
+	if n < NofileSafe {
+		return fmt.Errorf("%w: %d, below %d, raise the 'nofile' hard limit in /etc/security/limits.conf "+
+			"or DefaultLimitNOFILE in /etc/systemd/system.conf", ErrLowNofile, n, NofileSafe)
+	}
+
+	return nil
+}
+
+// checkNofile only reads the open file descriptor limits, as the hard
+// limit is what RaiseNofile raises the soft limit to when launching.
+func checkNofile(_ *config.Config) (string, error) {
+	var rl unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &rl); err != nil {
+		return "", err
+	}
+
+	if err := checkNofileLimit(rl.Max); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("soft %d, hard %d", rl.Cur, rl.Max), nil
+}
+
+func checkRegistry(cfg *config.Config) (string, error) {
+	var res string
+
+	for _, bt := range []roblox.BinaryType{roblox.Player, roblox.Studio} {
+		pfx, err := wine.New(BinaryPrefixDir(bt), BinaryConfig(cfg, bt).WineRoot)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", bt, err)
+		}
+
+		err = pfx.CheckRegistry()
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			res += fmt.Sprintf("%s: not initialized ", bt)
+		case err != nil:
+			return "", fmt.Errorf("%s: %w (run 'vinegar %s repair')", bt, err, strings.ToLower(bt.String()))
+		default:
+			res += fmt.Sprintf("%s: ok ", bt)
+		}
+	}
+
+	return res, nil
+}
+
+func checkSplashLogo(cfg *config.Config) (string, error) {
+	if cfg.Splash.LogoPath == "" {
+		return "default", nil
+	}
+
+	format, err := cfg.Splash.CheckLogo()
+	if err != nil {
+		return "", fmt.Errorf("%w, the default logo will be used", err)
+	}
+
+	return fmt.Sprintf("%s: %s", cfg.Splash.LogoPath, format), nil
+}
+
+func checkOwnership(_ *config.Config) (string, error) {
+	for _, bt := range []roblox.BinaryType{roblox.Player, roblox.Studio} {
+		if err := CheckOwnership(BinaryPrefixDir(bt)); err != nil {
+			return "", fmt.Errorf("%s: %w", bt, err)
+		}
+	}
+
+	return "owned by the current user", nil
+}
+
+func checkFilesystems(_ *config.Config) (string, error) {
+	var res string
+
+	for _, bt := range []roblox.BinaryType{roblox.Player, roblox.Studio} {
+		fs, err := CheckFilesystem(BinaryPrefixDir(bt))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", bt, err)
+		}
+
+		res += fmt.Sprintf("%s: %s ", bt, fs)
+	}
+
+	return res, nil
+}
+
+func checkGamepads(_ *config.Config) (string, error) {
+	pads, _ := filepath.Glob("/dev/input/by-id/*-event-joystick")
+	if len(pads) == 0 {
+		return "no gamepads connected", nil
+	}
+
+	for _, p := range pads {
+		if err := unix.Access(p, unix.R_OK); err == nil {
+			continue
+		}
+
+		if sysinfo.InFlatpak {
+			return "", fmt.Errorf("%s is not accessible, allow device access with "+
+				"'flatpak override --user --device=all org.vinegarhq.Vinegar'", filepath.Base(p))
+		}
+
+		return "", fmt.Errorf("%s is not accessible, ensure your user is in the 'input' group "+
+			"or that udev grants your session access to it", filepath.Base(p))
+	}
+
+	return fmt.Sprintf("%d gamepads accessible", len(pads)), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/vinegarhq/vinegar/wine/dxvk"
+)
+
+// DxvkCommand installs or uninstalls DXVK in the Binary's Wineprefix, as
+// named by the dxvk subcommand's only argument. Uninstalling is refused
+// while the configuration's dxvk option is enabled, as DXVK would be
+// installed again when Roblox is next launched.
+func (b *Binary) DxvkCommand(args ...string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	if PrefixRunning(b.Prefix.Dir()) {
+		return errors.New("wineprefix is in use, refusing to modify dxvk")
+	}
+
+	switch args[0] {
+	case "install":
+		ver, err := b.InstallDxvk()
+		if err != nil {
+			return fmt.Errorf("install: %w", err)
+		}
+
+		if !b.Config.Dxvk {
+			slog.Warn("DXVK is disabled by the configuration, and will be uninstalled on the next launch")
+		}
+		fmt.Println("Installed DXVK", ver)
+	case "uninstall":
+		if b.Config.Dxvk {
+			return errors.New("dxvk is enabled by the configuration, disable dxvk to uninstall it")
+		}
+
+		if err := dxvk.Remove(b.Prefix); err != nil {
+			return fmt.Errorf("uninstall: %w", err)
+		}
+		b.State.DxvkVersion = ""
+
+		fmt.Println("Uninstalled DXVK")
+	default:
+		return ErrUsage
+	}
+
+	if err := b.GlobalState.Save(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/vinegarhq/vinegar/wine/dxvk"
+	"github.com/vinegarhq/vinegar/wine/vkd3d"
+)
+
+// ExecArgs returns the program and arguments to run the named file
+// with inside a Wineprefix, based on it's extension (case-insensitive):
+//
+//   - .msi installers are installed with 'msiexec /i'
+//   - .bat and .cmd scripts are run with 'cmd /c'
+//   - anything else, such as .exe files, is run as-is
+func ExecArgs(name string, args ...string) (string, []string) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".msi":
+		return "msiexec", append([]string{"/i", name}, args...)
+	case ".bat", ".cmd":
+		return "cmd", append([]string{"/c", name}, args...)
+	}
+
+	return name, args
+}
+
+// ExecCommand parses the exec subcommand's arguments and runs the
+// given program within the Binary's Wineprefix.
+func (b *Binary) ExecCommand(args ...string) error {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	raw := flags.Bool("raw", false, "run the program as-is, without detecting installers and scripts")
+	asRoblox := flags.Bool("as-roblox", false, "run the program with the same environment as Roblox")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 1 {
+		return ErrUsage
+	}
+
+	name, pargs := flags.Arg(0), flags.Args()[1:]
+	if !*raw {
+		name, pargs = ExecArgs(name, pargs...)
+	}
+
+	if *asRoblox {
+		if err := b.LaunchEnv(); err != nil {
+			return err
+		}
+	}
+
+	return b.Prefix.Wine(name, pargs...).Run()
+}
+
+// LaunchEnv sets the same environment that Roblox is launched with by
+// Main and Setup, without setting up or launching Roblox.
+func (b *Binary) LaunchEnv() error {
+	if err := b.SetupEnv(); err != nil {
+		return err
+	}
+
+	nofile, err := RaiseNofile()
+	if err != nil {
+		slog.Warn("Wine and Roblox may run out of file descriptors", "error", err)
+	}
+	b.SetupSync(nofile)
+
+	if b.Config.Dxvk {
+		dxvk.Setenv()
+	}
+
+	if b.Config.Vkd3dPath != "" {
+		vkd3d.Setenv()
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExecArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantArgs []string
+	}{
+		{"setup.MSI", "msiexec", []string{"/i", "setup.MSI", "/q"}},
+		{"run.bat", "cmd", []string{"/c", "run.bat", "/q"}},
+		{"run.cmd", "cmd", []string{"/c", "run.cmd", "/q"}},
+		{"setup.exe", "setup.exe", []string{"/q"}},
+	}
+
+	for _, tt := range tests {
+		name, args := ExecArgs(tt.name, "/q")
+		if name != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
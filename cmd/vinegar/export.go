@@ -0,0 +1,213 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/state"
+	"github.com/vinegarhq/vinegar/roblox"
+)
+
+// ExportFormat is the version of the export archive's layout,
+// incremented whenever it changes incompatibly.
+const ExportFormat = 1
+
+var ErrExportFormat = errors.New("unsupported export format")
+
+// ExportManifest describes the machine an export archive was made on.
+type ExportManifest struct {
+	Format  int    `json:"format"`
+	Version string `json:"version"`
+	Home    string `json:"home"`
+}
+
+// Export writes the configuration file and the state to a zip archive
+// at the named path. Wineprefixes and Roblox installations are not included.
+func Export(name string) error {
+	home, _ := os.UserHomeDir()
+	files := make(map[string][]byte)
+
+	m, err := json.Marshal(ExportManifest{
+		Format:  ExportFormat,
+		Version: Version,
+		Home:    home,
+	})
+	if err != nil {
+		return err
+	}
+	files["manifest.json"] = m
+
+	c, err := os.ReadFile(ConfigPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	files["config.toml"] = c
+
+	s, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	files["state.json"], err = json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for n, b := range files {
+		w, err := zw.Create(n)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("Exported configuration and state", "path", name)
+	return nil
+}
+
+// Import replaces the configuration file and the state with the ones
+// from the named export archive, translating the exporting machine's
+// home directory to the current one. The previous configuration file
+// is kept with a '.bak' suffix.
+func Import(name string) error {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+
+		files[f.Name] = string(b)
+	}
+
+	var m ExportManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &m); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+
+	if m.Format != ExportFormat {
+		return fmt.Errorf("%w: %d", ErrExportFormat, m.Format)
+	}
+
+	if m.Version != Version {
+		slog.Warn("Export was made with a different version of Vinegar", "version", m.Version)
+	}
+
+	home, _ := os.UserHomeDir()
+	if m.Home != "" && m.Home != home {
+		slog.Info("Translating home directory", "from", m.Home, "to", home)
+		for n, c := range files {
+			files[n] = strings.ReplaceAll(c, m.Home, home)
+		}
+	}
+
+	var cfg config.Config
+	if _, err := toml.Decode(files["config.toml"], &cfg); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	warnMachineSpecific(&cfg)
+
+	var s state.State
+	if err := json.Unmarshal([]byte(files["state.json"]), &s); err != nil {
+		return fmt.Errorf("state: %w", err)
+	}
+	localizeState(&s)
+
+	if _, err := os.Stat(ConfigPath); err == nil {
+		if err := os.Rename(ConfigPath, ConfigPath+".bak"); err != nil {
+			return err
+		}
+	}
+
+	if err := dirs.Mkdirs(filepath.Dir(ConfigPath)); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(ConfigPath, []byte(files["config.toml"]), 0o644); err != nil {
+		return err
+	}
+
+	if err := s.Save(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	slog.Info("Imported configuration and state", "path", name)
+	return nil
+}
+
+// localizeState removes the parts of the imported state that describe
+// installations which are not present on this machine, so that they
+// will be installed on the next launch.
+func localizeState(s *state.State) {
+	for bt, bs := range map[roblox.BinaryType]*state.Binary{
+		roblox.Player: &s.Player,
+		roblox.Studio: &s.Studio,
+	} {
+		if _, err := os.Stat(filepath.Join(dirs.Versions, bs.Version)); bs.Version == "" || err != nil {
+			slog.Info("Imported deployment is not installed", "binary", bt, "guid", bs.Version)
+			bs.Version = ""
+			bs.Packages = nil
+			bs.Deployment = nil
+		}
+
+		if _, err := os.Stat(BinaryPrefixDir(bt)); err != nil {
+			bs.DxvkVersion = ""
+			bs.WebView = ""
+		}
+	}
+}
+
+func warnMachineSpecific(cfg *config.Config) {
+	for bt, b := range map[string]config.Binary{"player": cfg.Player, "studio": cfg.Studio} {
+		if b.WineRoot != "" {
+			slog.Warn("Imported wineroot may not exist on this machine", "binary", bt, "wineroot", b.WineRoot)
+		}
+
+		if b.Launcher != "" {
+			slog.Warn("Imported launcher may not exist on this machine", "binary", bt, "launcher", b.Launcher)
+		}
+
+		if b.ForcedGpu != "" && b.ForcedGpu != "integrated" && b.ForcedGpu != "prime-discrete" {
+			slog.Warn("Imported GPU selection is specific to the exporting machine", "binary", bt, "gpu", b.ForcedGpu)
+		}
+	}
+
+	if cfg.Splash.LogoPath != "" {
+		slog.Warn("Imported splash logo may not exist on this machine", "path", cfg.Splash.LogoPath)
+	}
+}
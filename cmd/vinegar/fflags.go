@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/roblox"
+)
+
+var ErrNotInstalled = errors.New("roblox is not installed")
+
+// PrintFFlags writes the FFlags currently applied to the installed
+// Binary's settings file to w, in the configuration's format.
+func (b *Binary) PrintFFlags(w io.Writer) error {
+	if b.State.Version == "" {
+		return ErrNotInstalled
+	}
+	b.Dir = filepath.Join(dirs.Versions, b.State.Version)
+
+	ff, err := roblox.ReadFFlags(b.SettingsFile())
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(ff))
+	for name := range ff {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s, ok := ff[name].(string); ok {
+			fmt.Fprintf(w, "%s = %q\n", name, s)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s = %v\n", name, ff[name])
+	}
+
+	return nil
+}
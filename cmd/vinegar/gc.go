@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/state"
+	"github.com/vinegarhq/vinegar/roblox"
+	"github.com/vinegarhq/vinegar/wine"
+)
+
+// RobloxCacheDirs is a list of directories relative to the Wineprefix's
+// AppData directory that Roblox uses for caching web and asset data.
+var RobloxCacheDirs = []string{
+	filepath.Join("Local", "Roblox", "http"),
+	filepath.Join("Local", "Roblox", "rbx-storage"),
+	filepath.Join("Local", "Temp", "Roblox"),
+}
+
+// GC removes Wineprefix temporary files, package downloads unused by
+// any installed version, log files older than the configuration's log
+// retention, and if robloxCache is set, Roblox's cache, returning the
+// amount of bytes reclaimed. Installed versions and the Wineprefix's
+// registry are never touched.
+func GC(cfg *config.Config, robloxCache bool) (int64, error) {
+	var paths []string
+
+	for _, bt := range []roblox.BinaryType{roblox.Player, roblox.Studio} {
+		pfx, err := wine.New(BinaryPrefixDir(bt), BinaryConfig(cfg, bt).WineRoot)
+		if err != nil {
+			return 0, fmt.Errorf("%s prefix: %w", bt, err)
+		}
+
+		if PrefixRunning(pfx.Dir()) {
+			return 0, fmt.Errorf("%s wineprefix is in use, refusing to clean it", bt)
+		}
+
+		ad, err := pfx.AppDataDir()
+		if errors.Is(err, wine.ErrPrefixNotInit) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("%s appdata: %w", bt, err)
+		}
+
+		// Wine does not recreate the temporary directories, only
+		// remove their contents.
+		paths = append(paths, dirEntries(filepath.Join(ad, "Local", "Temp"))...)
+		paths = append(paths, dirEntries(filepath.Join(pfx.Dir(), "drive_c", "windows", "temp"))...)
+
+		if robloxCache {
+			for _, d := range RobloxCacheDirs {
+				paths = append(paths, dirEntries(filepath.Join(ad, d))...)
+			}
+		}
+	}
+
+	s, err := state.Load()
+	if err != nil {
+		return 0, fmt.Errorf("load state: %w", err)
+	}
+
+	for _, p := range dirEntries(dirs.Downloads) {
+		if !slices.Contains(s.Packages(), filepath.Base(p)) {
+			paths = append(paths, p)
+		}
+	}
+
+	if cfg.LogRetention > 0 {
+		retention := time.Duration(cfg.LogRetention) * 24 * time.Hour
+
+		logs, _ := os.ReadDir(dirs.Logs)
+		for _, l := range logs {
+			fi, err := l.Info()
+			if err != nil || time.Since(fi.ModTime()) < retention {
+				continue
+			}
+
+			paths = append(paths, filepath.Join(dirs.Logs, l.Name()))
+		}
+	}
+
+	return removePaths(paths)
+}
+
+// ClearCache removes only Roblox's web and asset cache, as listed by
+// RobloxCacheDirs, from the Wineprefixes, returning the amount of bytes
+// reclaimed. Installations, settings and logins are left untouched.
+func ClearCache(cfg *config.Config) (int64, error) {
+	var paths []string
+
+	for _, bt := range []roblox.BinaryType{roblox.Player, roblox.Studio} {
+		pfx, err := wine.New(BinaryPrefixDir(bt), BinaryConfig(cfg, bt).WineRoot)
+		if err != nil {
+			return 0, fmt.Errorf("%s prefix: %w", bt, err)
+		}
+
+		if PrefixRunning(pfx.Dir()) {
+			return 0, fmt.Errorf("%s wineprefix is in use, refusing to clear Roblox's cache", bt)
+		}
+
+		ad, err := pfx.AppDataDir()
+		if errors.Is(err, wine.ErrPrefixNotInit) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("%s appdata: %w", bt, err)
+		}
+
+		for _, d := range RobloxCacheDirs {
+			paths = append(paths, dirEntries(filepath.Join(ad, d))...)
+		}
+	}
+
+	return removePaths(paths)
+}
+
+// removePaths removes all the named paths, returning the
+// amount of bytes reclaimed.
+func removePaths(paths []string) (int64, error) {
+	var reclaimed int64
+	for _, p := range paths {
+		size, err := DirSize(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return reclaimed, err
+		}
+
+		slog.Info("Removing", "path", p, "size", HumanSize(size))
+
+		if err := os.RemoveAll(p); err != nil {
+			return reclaimed, err
+		}
+
+		reclaimed += size
+	}
+
+	return reclaimed, nil
+}
+
+// GCCommand parses the gc subcommand's arguments and runs GC.
+func GCCommand(cfg *config.Config, args ...string) error {
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	robloxCache := flags.Bool("roblox-cache", false, "also remove Roblox's web and asset cache")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	n, err := GC(cfg, *robloxCache)
+	fmt.Println("Reclaimed", HumanSize(n))
+	return err
+}
+
+// ClearCacheCommand runs ClearCache and reports the reclaimed space.
+func ClearCacheCommand(cfg *config.Config) error {
+	n, err := ClearCache(cfg)
+	fmt.Println("Reclaimed", HumanSize(n))
+	return err
+}
+
+// dirEntries returns the paths of all the entries within the named
+// directory, or nothing if it could not be read.
+func dirEntries(dir string) (paths []string) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+
+	return
+}
+
+// BinaryConfig returns the named configuration's Binary
+// configuration for the given BinaryType.
+func BinaryConfig(cfg *config.Config, bt roblox.BinaryType) *config.Binary {
+	if bt == roblox.Studio {
+		return &cfg.Studio
+	}
+
+	return &cfg.Player
+}
+
+// DirSize returns the total size of all the regular files
+// within the named path.
+func DirSize(name string) (size int64, err error) {
+	err = filepath.WalkDir(name, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		size += fi.Size()
+		return nil
+	})
+
+	return
+}
+
+// HumanSize formats the given amount of bytes to a human readable form.
+func HumanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
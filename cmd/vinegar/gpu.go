@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/sysinfo"
+	"golang.org/x/sys/unix"
+)
+
+// GPUProbeTimeout is the maximum amount of time the
+// Vulkan query of ProbeGPU may take.
+const GPUProbeTimeout = 5 * time.Second
+
+var (
+	ErrGPUProbe    = errors.New("graphics stack is not functional")
+	ErrGPURejected = errors.New("graphics card is known to be rejected by roblox")
+)
+
+// GPUIssue is a graphics card or driver that Roblox is known to
+// refuse rendering with on Linux, and how it can be worked around.
+type GPUIssue struct {
+	Driver     string   // Base driver name, any if empty
+	Vendor     string   // PCI vendor ID, any if empty
+	Products   []string // PCI device IDs, any of the vendor's if empty
+	Reason     string
+	Workaround string
+}
+
+// KnownGPUIssues is the list of graphics cards and drivers known
+// to be rejected by Roblox, checked in order.
+var KnownGPUIssues = []GPUIssue{
+	{
+		Driver:     "nouveau",
+		Reason:     "nouveau lacks the Vulkan support required by DXVK on most cards",
+		Workaround: "install the proprietary NVIDIA driver, or set renderer to \"OpenGL\" and dxvk to false",
+	},
+	{
+		Driver:     "radeon",
+		Reason:     "pre-GCN AMD cards have no Vulkan support",
+		Workaround: "set renderer to \"OpenGL\" and dxvk to false, or use the amdgpu driver if the card supports it",
+	},
+	{
+		Vendor:     "0x1af4",
+		Reason:     "virtio virtual graphics cards only support software rendering",
+		Workaround: "pass a physical graphics card to the virtual machine, or set renderer to \"OpenGL\" and dxvk to false",
+	},
+	{
+		Vendor:     "0x15ad",
+		Reason:     "VMware virtual graphics cards lack the D3D11 feature level required by Roblox",
+		Workaround: "pass a physical graphics card to the virtual machine, or set renderer to \"OpenGL\" and dxvk to false",
+	},
+	{
+		Driver:     "simple-framebuffer",
+		Reason:     "no graphics driver is loaded for the card",
+		Workaround: "install your graphics card's driver, or enable kernel modesetting for the NVIDIA driver",
+	},
+}
+
+// Match determines if the issue applies to the given card.
+func (i *GPUIssue) Match(c sysinfo.Card) bool {
+	if i.Driver != "" && i.Driver != c.Driver {
+		return false
+	}
+
+	if i.Vendor != "" && i.Vendor != c.Vendor {
+		return false
+	}
+
+	return len(i.Products) == 0 || slices.Contains(i.Products, c.Product)
+}
+
+// FindGPUIssue returns the first of KnownGPUIssues that
+// applies to the given card, if any.
+func FindGPUIssue(c sysinfo.Card) *GPUIssue {
+	for i := range KnownGPUIssues {
+		if KnownGPUIssues[i].Match(c) {
+			return &KnownGPUIssues[i]
+		}
+	}
+
+	return nil
+}
+
+// vulkanICDDirs is a list of directories holding the Vulkan
+// driver manifests used by the Vulkan loader.
+var vulkanICDDirs = []string{
+	"/usr/share/vulkan/icd.d",
+	"/usr/local/share/vulkan/icd.d",
+	"/etc/vulkan/icd.d",
+}
+
+// ProbeGPU quickly checks that the graphics stack is usable by Wine,
+// which requires a GPU, an accessible DRM render node and a Vulkan
+// driver. If vulkaninfo is installed, the Vulkan driver is also queried.
+func ProbeGPU() (string, error) {
+	if len(sysinfo.Cards) == 0 {
+		return "", fmt.Errorf("%w: no graphics cards found", ErrGPUProbe)
+	}
+
+	nodes, _ := filepath.Glob("/dev/dri/renderD*")
+	accessible := false
+	for _, n := range nodes {
+		if unix.Access(n, unix.R_OK|unix.W_OK) == nil {
+			accessible = true
+			break
+		}
+	}
+	if !accessible {
+		return "", fmt.Errorf("%w: no accessible render node in /dev/dri, "+
+			"ensure your user is in the 'render' or 'video' group", ErrGPUProbe)
+	}
+
+	if !vulkanDriverFound() {
+		return "", fmt.Errorf("%w: no vulkan drivers installed, "+
+			"install your distribution's vulkan driver package (such as mesa-vulkan-drivers)", ErrGPUProbe)
+	}
+
+	if _, err := exec.LookPath("vulkaninfo"); err != nil {
+		return "vulkan driver present", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), GPUProbeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "vulkaninfo", "--summary").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: vulkaninfo: %w: %s", ErrGPUProbe, err, lastLine(out))
+	}
+
+	return "vulkan functional", nil
+}
+
+// vulkanDriverFound determines if any Vulkan driver manifests exist,
+// or if the Vulkan loader has been explicitly given drivers to use.
+func vulkanDriverFound() bool {
+	if os.Getenv("VK_DRIVER_FILES") != "" || os.Getenv("VK_ICD_FILENAMES") != "" {
+		return true
+	}
+
+	dirs := vulkanICDDirs
+	if sysinfo.InFlatpak {
+		dirs = append(dirs, "/usr/lib/x86_64-linux-gnu/GL/vulkan/icd.d")
+	}
+
+	for _, d := range dirs {
+		if m, _ := filepath.Glob(filepath.Join(d, "*.json")); len(m) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lastLine(b []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	return lines[len(lines)-1]
+}
+
+func checkGPU(_ *config.Config) (string, error) {
+	return ProbeGPU()
+}
+
+func checkGPUIssues(_ *config.Config) (string, error) {
+	var found []string
+	for _, c := range sysinfo.Cards {
+		if i := FindGPUIssue(c); i != nil {
+			found = append(found, fmt.Sprintf("card %s: %s; %s", c, i.Reason, i.Workaround))
+		}
+	}
+
+	if len(found) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrGPURejected, strings.Join(found, ", "))
+	}
+
+	return fmt.Sprintf("no known issues with %d cards", len(sysinfo.Cards)), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+
+	"github.com/vinegarhq/vinegar/config"
+)
+
+// RunHook runs the given hook, if configured, with the Binary's current
+// deployment as it's argument and environment. The hook's output is
+// logged, and it's failure only returned if the hook is required.
+func (b *Binary) RunHook(name string, h *config.Hook) error {
+	if h.Command == "" {
+		return nil
+	}
+
+	p, err := h.Path()
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+
+	b.SetMessage("Running " + name + " hook")
+	slog.Info("Running hook", "name", name, "command", h.Command)
+
+	cmd := exec.Command(p, h.Args(b.Deploy.GUID)...)
+	cmd.Env = append(os.Environ(),
+		"ROBLOX_BINARY="+b.Type.String(),
+		"ROBLOX_VERSION="+b.Deploy.GUID,
+		"ROBLOX_CHANNEL="+b.Deploy.Channel,
+		"ROBLOX_DIR="+b.Dir,
+	)
+
+	out, err := cmd.CombinedOutput()
+
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		slog.Info(s.Text(), "hook", name)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if h.Required {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+
+	slog.Warn("Hook failed", "name", name, "error", err)
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/internal/netutil"
+	"github.com/vinegarhq/vinegar/roblox/api"
+	"github.com/vinegarhq/vinegar/roblox/bootstrapper"
+)
+
+// headerTransport adds the given headers to all requests made
+// through the underlying RoundTripper.
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.header {
+		req.Header[k] = v
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// UserAgent returns the configured User-Agent, or Vinegar's
+// own if one isn't set.
+func UserAgent(cfg *config.Config) string {
+	if cfg.UserAgent != "" {
+		return cfg.UserAgent
+	}
+
+	return "Vinegar/" + Version + " (+https://vinegarhq.org)"
+}
+
+// SetupHTTP sets the HTTP client used for all of Vinegar's requests
+// based on the configuration.
+func SetupHTTP(cfg *config.Config) error {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(cfg.CACertificates) > 0 {
+		pool, err := netutil.CertPool(cfg.CACertificates...)
+		if err != nil {
+			return fmt.Errorf("ca certificates: %w", err)
+		}
+
+		slog.Warn("Trusting additional CA certificates", "files", cfg.CACertificates)
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	h := make(http.Header)
+	h.Set("User-Agent", UserAgent(cfg))
+	for k, v := range cfg.Headers {
+		h.Set(k, v)
+	}
+
+	c := &http.Client{Transport: &headerTransport{base: t, header: h}}
+	netutil.SetClient(c)
+	api.SetClient(c)
+
+	if len(cfg.Mirrors) > 0 {
+		bootstrapper.Mirrors = make([]string, len(cfg.Mirrors))
+		for i, m := range cfg.Mirrors {
+			bootstrapper.Mirrors[i] = strings.TrimSuffix(m, "/")
+		}
+		slog.Info("Using configured deploy mirrors", "mirrors", bootstrapper.Mirrors)
+	}
+
+	return nil
+}
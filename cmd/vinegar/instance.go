@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/wine"
+)
+
+// IsolateInstance makes the Binary run within an overlay of it's
+// Wineprefix unique to this instance, so that the instance's settings
+// and autosaves do not interfere with other running instances. The
+// returned function stops the instance's Wineprefix and removes it,
+// and should be called once the instance has exited.
+func (b *Binary) IsolateInstance() (func(), error) {
+	dir := filepath.Join(dirs.Data, "instances",
+		strings.ToLower(b.Alias)+"-"+strconv.Itoa(os.Getpid()))
+
+	slog.Info("Isolating instance", "dir", dir)
+	b.SetMessage("Isolating " + b.Alias)
+
+	if err := Overlay(b.Prefix.Dir(), dir); err != nil {
+		return nil, err
+	}
+
+	pfx, err := wine.New(dir, b.Config.WineRoot)
+	if err != nil {
+		return nil, err
+	}
+	pfx.Server = b.Prefix.Server
+	pfx.Stdout = b.Prefix.Stdout
+	pfx.Stderr = b.Prefix.Stderr
+	b.Prefix = pfx
+
+	return func() {
+		slog.Info("Removing isolated instance", "dir", dir)
+
+		_ = pfx.Kill()
+		if mounted(dir) {
+			if err := unmount(dir); err != nil {
+				slog.Error("Failed to unmount isolated instance", "error", err)
+				return
+			}
+		}
+
+		for _, d := range []string{dir, overlayDir(dir)} {
+			if err := os.RemoveAll(d); err != nil {
+				slog.Error("Failed to remove isolated instance", "error", err)
+			}
+		}
+	}, nil
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/vinegarhq/vinegar/config"
 	"github.com/vinegarhq/vinegar/config/editor"
 	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/events"
+	"github.com/vinegarhq/vinegar/internal/state"
 	"github.com/vinegarhq/vinegar/roblox"
 	"golang.org/x/term"
 )
@@ -19,122 +24,369 @@ import (
 var (
 	BinPrefix  string
 	ConfigPath string
+	EventsPath string
 	FirstRun   bool
+	LogPath    string
+	Profile    string
+	EnvProfile string
+	Preset     string
+	Channel    string
+	Place      string
+	Job        string
 	Version    string
+
+	ReinitWebView bool
+	InstallOnly   bool
+	Quiet         bool
 )
 
 func init() {
 	flag.StringVar(&ConfigPath, "config", filepath.Join(dirs.Config, "config.toml"), "config.toml file which should be used")
 	flag.BoolVar(&FirstRun, "firstrun", false, "to trigger first run behavior")
+	flag.StringVar(&LogPath, "log-file", "", "file to log to instead of the logs directory, or '-' for standard error")
+	flag.StringVar(&Profile, "profile", "", "name of the Roblox settings and login profile to use")
+	flag.StringVar(&EnvProfile, "profile-env", "", "name of the configuration's environment profile to use")
+	flag.StringVar(&Preset, "preset", "", "name of the binary's channel preset to use")
+	flag.StringVar(&Channel, "channel", "", "deployment channel to use, overriding the configuration and Roblox")
+	flag.StringVar(&Place, "place", "", "id of the experience for the Player to join")
+	flag.StringVar(&Job, "job", "", "id of the server of the experience given by -place to join")
+	flag.StringVar(&EventsPath, "events", "", "unix socket path to serve progress events as JSON lines on")
+	flag.BoolVar(&ReinitWebView, "reinit-webview", false, "reinstall WebView before launching, to fix the in-app browser")
+	flag.BoolVar(&InstallOnly, "install-only", false, "set up and verify the installation without launching Roblox")
+	flag.BoolVar(&Quiet, "quiet", false, "only print errors to the terminal")
+}
+
+// ErrUsage is returned when Vinegar was given invalid arguments,
+// and results in the usage being printed.
+var ErrUsage = errors.New("invalid usage")
+
+// parseFlags parses a subcommand's arguments with the named FlagSet,
+// which must not exit on errors, returning [ErrUsage] if help was requested.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ErrUsage
+	}
+
+	return err
 }
 
 func usage() {
-	fmt.Fprintln(os.Stderr, "usage: vinegar [-config filepath] player|studio exec|run [args...]")
+	fmt.Fprintln(os.Stderr, "usage: vinegar [-config filepath] [-quiet] [-events socket] [-log-file path] [-profile name] [-profile-env name] [-preset name] [-channel name] [-place id [-job id]] [-reinit-webview] [-install-only] player|studio run|bench [args...]")
 	fmt.Fprintln(os.Stderr, "usage: vinegar [-config filepath] player|studio kill|winetricks")
-	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] sysinfo")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] studio clear-cookie")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio fflags|rollback")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio exec [-raw] [-as-roblox] file [args...]")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio dxvk install|uninstall (uninstall requires dxvk = false)")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio backup [-list]")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio restore name")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] player|studio repair [-no-prefix] [-no-webview] [-no-verify] [-no-fflags]")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] sysinfo [-o bugreport.zip]")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] doctor")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] gc [-roblox-cache]")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] clear-cache")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] export|import file")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] setup")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] config validate [file]")
+	fmt.Fprintln(os.Stderr, "       vinegar config import bloxstrap-or-sober.json")
+	fmt.Fprintln(os.Stderr, "       vinegar [-config filepath] config paths")
 	fmt.Fprintln(os.Stderr, "       vinegar delete|edit|version")
-	os.Exit(1)
 }
 
 func main() {
 	flag.Parse()
 
-	cmd := flag.Arg(0)
-	args := flag.Args()
+	if Quiet {
+		SetQuietLogger(nil)
+	}
 
-	switch cmd {
-	case "delete", "edit", "version":
-		switch cmd {
-		case "delete":
-			slog.Info("Deleting Wineprefixes and Roblox Binary deployments!")
+	err := Run(flag.Args()...)
+	if errors.Is(err, ErrUsage) {
+		usage()
+		os.Exit(1)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+}
 
-			if err := os.RemoveAll(dirs.Prefixes); err != nil {
-				log.Fatalf("remove %s: %s", dirs.Prefixes, err)
-			}
-		case "edit":
-			if err := editor.Edit(ConfigPath); err != nil {
-				log.Fatalf("edit %s: %s", ConfigPath, err)
-			}
-		case "version":
-			fmt.Println("Vinegar", Version)
-		}
-	case "player", "studio", "sysinfo":
-		// Remove after a few releases
-		if _, err := os.Stat(dirs.Prefix); err == nil {
-			slog.Info("Deleting deprecated old Wineprefix!")
-			if err := os.RemoveAll(dirs.Prefix); err != nil {
-				log.Fatalf("delete old prefix %s: %s", dirs.Prefix, err)
-			}
+// Run runs the command given by args, the non-flag command-line
+// arguments, returning any error that occured instead of exiting.
+func Run(args ...string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "delete", "edit", "setup", "version", "export", "import", "config":
+		return RunCommand(args...)
+	case "player", "studio", "sysinfo", "doctor", "gc", "clear-cache":
+	default:
+		return ErrUsage
+	}
+
+	// Remove after a few releases
+	if _, err := os.Stat(dirs.Prefix); err == nil {
+		slog.Info("Deleting deprecated old Wineprefix!")
+		if err := os.RemoveAll(dirs.Prefix); err != nil {
+			return fmt.Errorf("delete old prefix %s: %w", dirs.Prefix, err)
 		}
+	}
 
-		cfg, err := config.Load(ConfigPath)
-		if err != nil {
-			log.Fatalf("load config %s: %s", ConfigPath, err)
+	cfg, err := config.Load(ConfigPath)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", ConfigPath, err)
+	}
+
+	if err := SetupHTTP(&cfg); err != nil {
+		return err
+	}
+
+	var bt roblox.BinaryType
+	switch args[0] {
+	case "player":
+		bt = roblox.Player
+	case "studio":
+		bt = roblox.Studio
+	case "sysinfo":
+		if err := SysinfoCommand(&cfg, args[1:]...); err != nil {
+			return fmt.Errorf("sysinfo: %w", err)
+		}
+		return nil
+	case "doctor":
+		return Doctor(&cfg)
+	case "gc":
+		if err := GCCommand(&cfg, args[1:]...); err != nil {
+			return fmt.Errorf("gc: %w", err)
+		}
+		return nil
+	case "clear-cache":
+		if err := ClearCacheCommand(&cfg); err != nil {
+			return fmt.Errorf("clear cache: %w", err)
 		}
+		return nil
+	}
+
+	if len(args) < 2 {
+		return ErrUsage
+	}
 
-		var bt roblox.BinaryType
-		switch cmd {
-		case "player":
-			bt = roblox.Player
-		case "studio":
-			bt = roblox.Studio
-		case "sysinfo":
-			PrintSysinfo(&cfg)
-			os.Exit(0)
+	b, err := NewBinary(bt, &cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[1] {
+	case "exec":
+		if err := b.ExecCommand(args[2:]...); err != nil {
+			return fmt.Errorf("exec prefix %s: %w", bt, err)
+		}
+	case "kill":
+		b.Prefix.Kill()
+	case "repair":
+		if err := b.RepairCommand(args[2:]...); err != nil {
+			return fmt.Errorf("repair %s: %w", bt, err)
+		}
+	case "clear-cookie":
+		if bt != roblox.Studio {
+			return ErrUsage
 		}
 
-		b, err := NewBinary(bt, &cfg)
-		if err != nil {
-			log.Fatal(err)
+		if err := b.ClearCookie(); err != nil {
+			return fmt.Errorf("clear cookie: %w", err)
+		}
+	case "rollback":
+		if err := b.Rollback(); err != nil {
+			return fmt.Errorf("rollback %s: %w", bt, err)
+		}
+	case "backup":
+		if err := b.BackupCommand(args[2:]...); err != nil {
+			return fmt.Errorf("backup %s: %w", bt, err)
 		}
+	case "restore":
+		if err := b.RestoreCommand(args[2:]...); err != nil {
+			return fmt.Errorf("restore %s: %w", bt, err)
+		}
+	case "fflags":
+		if err := b.PrintFFlags(os.Stdout); err != nil {
+			return fmt.Errorf("fflags %s: %w", bt, err)
+		}
+	case "dxvk":
+		if err := b.DxvkCommand(args[2:]...); err != nil {
+			return fmt.Errorf("dxvk %s: %w", bt, err)
+		}
+	case "winetricks":
+		if err := b.Prefix.Winetricks(); err != nil {
+			return fmt.Errorf("exec winetricks %s: %w", bt, err)
+		}
+	case "run", "bench":
+		return b.RunCommand(args[1] == "bench", args[2:]...)
+	default:
+		return ErrUsage
+	}
 
-		switch flag.Arg(1) {
-		case "exec":
-			if len(args) < 2 {
-				usage()
-			}
+	return nil
+}
 
-			if err := b.Prefix.Wine(args[2], args[3:]...).Run(); err != nil {
-				log.Fatalf("exec prefix %s: %s", bt, err)
-			}
-		case "kill":
-			b.Prefix.Kill()
-		case "winetricks":
-			if err := b.Prefix.Winetricks(); err != nil {
-				log.Fatalf("exec winetricks %s: %s", bt, err)
-			}
-		case "run":
-			err = b.Main(args[2:]...)
-			if err == nil {
-				slog.Info("Goodbye")
-				os.Exit(0)
-			}
+// RunCommand runs the commands that do not require the configuration.
+func RunCommand(args ...string) error {
+	switch args[0] {
+	case "delete":
+		slog.Info("Deleting Wineprefixes and Roblox Binary deployments!")
 
-			// Only fatal print the error if we are in a terminal, otherwise
-			// display a dialog message.
-			if !cfg.Splash.Enabled || term.IsTerminal(int(os.Stderr.Fd())) {
-				log.Fatal(err)
-			}
+		if err := os.RemoveAll(dirs.Prefixes); err != nil {
+			return fmt.Errorf("remove %s: %w", dirs.Prefixes, err)
+		}
+	case "edit":
+		if err := editor.Edit(ConfigPath); err != nil {
+			return fmt.Errorf("edit %s: %w", ConfigPath, err)
+		}
+	case "setup":
+		if err := editor.Wizard(ConfigPath, os.Stdin, os.Stdout); err != nil {
+			return fmt.Errorf("setup %s: %w", ConfigPath, err)
+		}
+	case "config":
+		if len(args) == 3 && args[1] == "import" {
+			return ImportBootstrapper(args[2])
+		}
 
-			slog.Error(err.Error())
-			b.Splash.SetMessage("Oops!")
-			b.Splash.Dialog(fmt.Sprintf(DialogFailure, err), false)
-			os.Exit(1)
-		default:
-			usage()
+		if len(args) == 2 && args[1] == "paths" {
+			ConfigPaths(os.Stdout)
+			return nil
+		}
+
+		if len(args) < 2 || args[1] != "validate" {
+			return ErrUsage
+		}
+
+		name := ConfigPath
+		if len(args) > 2 {
+			name = args[2]
+		}
+
+		if err := config.ValidateFile(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Println(name, "is valid")
+	case "version":
+		fmt.Println("Vinegar", Version)
+
+		s, err := state.Load()
+		if err != nil {
+			fmt.Println("State:", err)
+			break
+		}
+		fmt.Println("Player:", s.Player.Deployment)
+		fmt.Println("Studio:", s.Studio.Deployment)
+	case "export", "import":
+		if len(args) < 2 {
+			return ErrUsage
+		}
+
+		fn := Export
+		if args[0] == "import" {
+			fn = Import
+		}
+
+		if err := fn(args[1]); err != nil {
+			return fmt.Errorf("%s %s: %w", args[0], args[1], err)
 		}
-	default:
-		usage()
 	}
+
+	return nil
 }
 
-func LogFile(name string) (*os.File, error) {
-	if err := dirs.Mkdirs(dirs.Logs); err != nil {
-		return nil, err
+// RunCommand runs the Binary with the given arguments, or with the
+// experience given by -place, handling failures by showing an error
+// dialog when possible. If bench is set, launch timings are reported.
+func (b *Binary) RunCommand(bench bool, args ...string) error {
+	if bench {
+		b.Bench = NewBench()
+	}
+
+	if Place != "" {
+		if b.Type != roblox.Player {
+			return errors.New("-place is only supported by the Player")
+		}
+
+		uri, err := PlaceURI(Place, Job)
+		if err != nil {
+			return err
+		}
+		args = []string{uri}
+	} else if Job != "" {
+		return errors.New("-job requires -place")
+	}
+
+	if EventsPath != "" {
+		var err error
+		b.Events, err = events.Listen(EventsPath)
+		if err != nil {
+			return fmt.Errorf("listen events %s: %w", EventsPath, err)
+		}
+		b.Events.Binary = b.Alias
 	}
 
+	err := b.Main(args...)
+	if err != nil {
+		b.Events.Send(events.Event{Type: events.Error, Message: err.Error()})
+	}
+	b.Events.Close()
+
+	if err == nil {
+		slog.Info("Goodbye")
+		return nil
+	}
+
+	// The log file has been closed, and may have been the
+	// only log output if quiet.
+	log.SetOutput(os.Stderr)
+
+	// Only return the error to be printed if we are in a terminal or no
+	// dialog can be shown, otherwise display a dialog message.
+	if !Interactive(b.GlobalConfig) || term.IsTerminal(int(os.Stderr.Fd())) {
+		return err
+	}
+
+	if errors.Is(err, ErrAlreadyRunning) {
+		b.ErrorDialog(err.Error())
+		return err
+	}
+
+	slog.Error(err.Error())
+	b.SetMessage("Oops!")
+	b.ErrorDialog(fmt.Sprintf(DialogFailure, err))
+	return err
+}
+
+// LogFile creates a new log file for the named binary in the logs directory,
+// or at the path given by the -log-file flag if it was set.
+func LogFile(name string) (*os.File, error) {
 	// name-2006-01-02T15:04:05Z07:00.log
 	path := filepath.Join(dirs.Logs, name+"-"+time.Now().Format(time.RFC3339)+".log")
+	if LogPath != "" {
+		path = LogPath
+	}
+
+	return createLog(name, path)
+}
+
+// WineLogFile creates a new log file for the output of Wine for the
+// named binary in the logs directory, or next to the path given by the
+// -log-file flag if it was set to a file.
+func WineLogFile(name string) (*os.File, error) {
+	// wine-name-2006-01-02T15:04:05Z07:00.log
+	path := filepath.Join(dirs.Logs, WineLogPrefix+name+"-"+time.Now().Format(time.RFC3339)+".log")
+	if LogPath != "" && LogPath != "-" {
+		path = strings.TrimSuffix(LogPath, ".log") + ".wine.log"
+	}
+
+	return createLog("wine "+name, path)
+}
+
+func createLog(name, path string) (*os.File, error) {
+	if err := dirs.Mkdirs(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
 
 	file, err := os.Create(path)
 	if err != nil {
@@ -145,3 +397,40 @@ func LogFile(name string) (*os.File, error) {
 
 	return file, nil
 }
+
+// ConfigPaths writes the configuration file paths that were considered,
+// in the order of their precedence, whether they exist, and the
+// configuration fields overriden by the environment to w.
+func ConfigPaths(w io.Writer) {
+	def := filepath.Join(dirs.Config, "config.toml")
+	sources := [][2]string{{"default", def}}
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			sources = [][2]string{{"-config", ConfigPath}, {"default (ignored)", def}}
+		}
+	})
+
+	for i, s := range sources {
+		status := "exists"
+		if _, err := os.Stat(s[1]); err != nil {
+			status = "missing"
+			if i == 0 {
+				status += ", using the default configuration"
+			}
+		}
+
+		fmt.Fprintf(w, "%d. %s: %s (%s)\n", i+1, s[0], s[1], status)
+	}
+
+	keys := config.EnvOverrides()
+	if len(keys) == 0 {
+		fmt.Fprintln(w, "No environment overrides")
+		return
+	}
+
+	fmt.Fprintln(w, "Environment overrides:")
+	for _, k := range keys {
+		fmt.Fprintf(w, "  * %s (%s)\n", k, config.EnvName(k))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrBadPlaceID = errors.New("place id must be a positive number")
+	ErrBadJobID   = errors.New("job id must be a server's UUID")
+)
+
+var placeIDPattern = regexp.MustCompile(`(?i)placeId(?:=|%3D)([0-9]+)`)
+
+var jobPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// PlaceURI returns the Roblox deep link URI to join the given place,
+// and if job is set, the given server of the place. The Player will
+// use it's own logged in session to join.
+func PlaceURI(place, job string) (string, error) {
+	if id, err := strconv.ParseUint(place, 10, 64); err != nil || id == 0 {
+		return "", fmt.Errorf("%w: %s", ErrBadPlaceID, place)
+	}
+
+	q := url.Values{"placeId": {place}}
+
+	if job != "" {
+		if !jobPattern.MatchString(job) {
+			return "", fmt.Errorf("%w: %s", ErrBadJobID, job)
+		}
+		q.Set("gameInstanceId", job)
+	}
+
+	return "roblox://experiences/start?" + q.Encode(), nil
+}
+
+// URIPlace returns the place ID of the experience to join from the
+// given launch URI, or an empty string if it does not join one.
+func URIPlace(uri string) string {
+	m := placeIDPattern.FindStringSubmatch(uri)
+	if m == nil {
+		return ""
+	}
+
+	return m[1]
+}
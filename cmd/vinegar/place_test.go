@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlaceURI(t *testing.T) {
+	uri, err := PlaceURI("1818", "")
+	if err != nil || uri != "roblox://experiences/start?placeId=1818" {
+		t.Fatalf("unexpected place uri %q: %v", uri, err)
+	}
+
+	uri, err = PlaceURI("1818", "c6a5a7c4-5b2e-4c39-9f0b-6e0e1d2f3a4b")
+	if err != nil || uri != "roblox://experiences/start?gameInstanceId=c6a5a7c4-5b2e-4c39-9f0b-6e0e1d2f3a4b&placeId=1818" {
+		t.Fatalf("unexpected job uri %q: %v", uri, err)
+	}
+
+	if _, err := PlaceURI("meow", ""); !errors.Is(err, ErrBadPlaceID) {
+		t.Error("expected place id check")
+	}
+
+	if _, err := PlaceURI("1818", "meow"); !errors.Is(err, ErrBadJobID) {
+		t.Error("expected job id check")
+	}
+}
+
+func TestURIPlace(t *testing.T) {
+	for uri, want := range map[string]string{
+		"roblox://experiences/start?placeId=1818": "1818",
+		"roblox-player:1+launchmode:play+placelauncherurl:https%3A%2F%2Fassetgame.roblox.com%2Fgame%2FPlaceLauncher.ashx%3Frequest%3DRequestGame%26placeId%3D1818": "1818",
+		"roblox-player:1+launchmode:app": "",
+	} {
+		if got := URIPlace(uri); got != want {
+			t.Errorf("URIPlace(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
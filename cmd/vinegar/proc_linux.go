@@ -5,9 +5,20 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"golang.org/x/sys/unix"
 )
 
+// ioClasses maps the configurable I/O scheduling class names
+// to the classes known by ioprio_set(2).
+var ioClasses = map[string]int{
+	"realtime":    1,
+	"best-effort": 2,
+	"idle":        3,
+}
+
 // CommFound loops over every directory in /proc and checks if the contents of
 // the comm file in the directory contains the named query.
 func CommFound(query string) bool {
@@ -24,3 +35,159 @@ func CommFound(query string) bool {
 
 	return false
 }
+
+// DescendantComm returns the PID of a process that is the root process or
+// one of it's descendants and has the named executable name as it's comm,
+// truncated to the 15 characters kept in comm.
+func DescendantComm(root int, name string) (int, bool) {
+	if len(name) > 15 {
+		name = name[:15]
+	}
+
+	comms, _ := filepath.Glob("/proc/*/comm")
+
+	for _, comm := range comms {
+		c, err := os.ReadFile(comm)
+		if err != nil || strings.TrimSuffix(string(c), "\n") != name {
+			continue
+		}
+
+		pid, err := strconv.Atoi(filepath.Base(filepath.Dir(comm)))
+		if err != nil {
+			continue
+		}
+
+		for p := pid; p > 1; p = parentPID(p) {
+			if p == root {
+				return pid, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// parentPID returns the parent PID of the given process, or 0 if
+// it could not be determined.
+func parentPID(pid int) int {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0
+	}
+
+	// The process name may contain spaces and parentheses, and
+	// is followed by the state and the parent PID.
+	i := strings.LastIndexByte(string(stat), ')')
+	if i < 0 {
+		return 0
+	}
+
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
+
+// CommRunning checks if any process' comm is exactly the named
+// executable name, truncated to the 15 characters kept in comm.
+func CommRunning(name string) bool {
+	if len(name) > 15 {
+		name = name[:15]
+	}
+
+	comms, _ := filepath.Glob("/proc/*/comm")
+
+	for _, comm := range comms {
+		c, err := os.ReadFile(comm)
+		if err == nil && strings.TrimSuffix(string(c), "\n") == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrefixRunning checks if any process is running within the named
+// Wineprefix directory, as determined by it's WINEPREFIX environment
+// variable. Processes whose environment cannot be read are ignored.
+func PrefixRunning(dir string) bool {
+	dir = filepath.Clean(dir)
+	environs, _ := filepath.Glob("/proc/*/environ")
+
+	for _, environ := range environs {
+		env, err := os.ReadFile(environ)
+		if err != nil {
+			continue
+		}
+
+		for _, kv := range strings.Split(string(env), "\x00") {
+			k, v, _ := strings.Cut(kv, "=")
+			if k == "WINEPREFIX" && filepath.Clean(v) == dir {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// tasks returns the thread IDs of the given process, as scheduling
+// priorities on Linux are applied per-thread.
+func tasks(pid int) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join("/proc", strconv.Itoa(pid), "task"))
+	if err != nil {
+		return nil, err
+	}
+
+	tids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+
+	return tids, nil
+}
+
+// SetNice sets the niceness of every thread of the given process.
+func SetNice(pid, nice int) error {
+	tids, err := tasks(pid)
+	if err != nil {
+		return err
+	}
+
+	for _, tid := range tids {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, tid, nice); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetIOPriority sets the I/O scheduling class and priority of
+// every thread of the given process.
+func SetIOPriority(pid, class, prio int) error {
+	const (
+		whoProcess = 1
+		classShift = 13
+	)
+
+	tids, err := tasks(pid)
+	if err != nil {
+		return err
+	}
+
+	for _, tid := range tids {
+		_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET,
+			whoProcess, uintptr(tid), uintptr(class<<classShift|prio))
+		if errno != 0 {
+			return errno
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultProfile is the name of the profile used when no profile
+// has been requested, which is shared by all launches.
+const DefaultProfile = "default"
+
+var (
+	ErrBadProfile   = errors.New("profile name must not be empty or contain path separators")
+	ErrProfileInUse = errors.New("cannot switch profiles while roblox is running with another profile")
+)
+
+// ProfileDir returns the directory that holds the named profile's
+// Roblox local settings and logins within the Binary's Wineprefix.
+func (b *Binary) ProfileDir(name string) string {
+	return filepath.Join(b.Prefix.Dir(), "profiles", name)
+}
+
+// SetupProfile makes the Binary's Roblox local data directory point
+// to the named profile's directory, creating it if it doesn't exist.
+//
+// If the Roblox local data directory has never been switched to a
+// profile, it will be kept as-is for the default profile, otherwise
+// it will be moved to become the default profile.
+//
+// As all profiles share the Wineprefix's single Roblox local data
+// directory, switching profiles is refused while the Binary is running.
+func (b *Binary) SetupProfile(name string) error {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	if name == "." || name == ".." || strings.ContainsRune(name, os.PathSeparator) {
+		return fmt.Errorf("%w: %s", ErrBadProfile, name)
+	}
+
+	ad, err := b.Prefix.AppDataDir()
+	if err != nil {
+		return fmt.Errorf("get appdata: %w", err)
+	}
+
+	local := filepath.Join(ad, "Local", "Roblox")
+	dir := b.ProfileDir(name)
+
+	fi, err := os.Lstat(local)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	isLink := err == nil && fi.Mode().Type() == os.ModeSymlink
+	if err == nil && !isLink && name == DefaultProfile {
+		return nil
+	}
+
+	if target, _ := os.Readlink(local); isLink && target == dir {
+		return nil
+	}
+
+	if CommRunning(b.Type.Executable()) {
+		return ErrProfileInUse
+	}
+
+	if err == nil && !isLink {
+		def := b.ProfileDir(DefaultProfile)
+
+		// The default profile already exists if Roblox has recreated its
+		// data directory after a profile was used, keep both of them.
+		if _, err := os.Stat(def); err == nil {
+			def = b.ProfileDir("recovered-" + time.Now().Format("20060102150405"))
+			slog.Warn("Default profile already exists, keeping Roblox data as another profile", "dest", def)
+		}
+
+		slog.Info("Moving shared Roblox data to profile", "path", local, "dest", def)
+
+		if err := os.MkdirAll(filepath.Dir(def), 0o755); err != nil {
+			return err
+		}
+
+		if err := os.Rename(local, def); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Switching Roblox profile", "name", name, "dir", dir)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create profile: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.Remove(local); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.Symlink(dir, local)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// teeHandler is a slog.Handler that passes records to all of it's
+// handlers that are enabled for the record's level.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, h := range t {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hs := make(teeHandler, len(t))
+	for i, h := range t {
+		hs[i] = h.WithAttrs(attrs)
+	}
+
+	return hs
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	hs := make(teeHandler, len(t))
+	for i, h := range t {
+		hs[i] = h.WithGroup(name)
+	}
+
+	return hs
+}
+
+// SetQuietLogger makes slog only print errors to standard error, while
+// logging everything to w, if non-nil. This is used by the -quiet flag.
+func SetQuietLogger(w io.Writer) {
+	stderr := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})
+	if w == nil {
+		slog.SetDefault(slog.New(stderr))
+		return
+	}
+
+	slog.SetDefault(slog.New(teeHandler{slog.NewTextHandler(w, nil), stderr}))
+}
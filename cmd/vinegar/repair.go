@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/splash"
+)
+
+// RepairStep is a named step of repairing a Binary's installation,
+// returning a description of the action taken.
+type RepairStep struct {
+	Name string
+	Run  func(*Binary) (string, error)
+}
+
+// RepairCommand parses the repair subcommand's arguments and runs each
+// of the repair steps that were not skipped, printing their results.
+// All steps are safe to run on a working installation.
+func (b *Binary) RepairCommand(args ...string) error {
+	flags := flag.NewFlagSet("repair", flag.ContinueOnError)
+	noPrefix := flags.Bool("no-prefix", false, "skip updating the wineprefix")
+	noWebView := flags.Bool("no-webview", false, "skip reinstalling WebView if it is broken")
+	noVerify := flags.Bool("no-verify", false, "skip verifying the installed Roblox version")
+	noFFlags := flags.Bool("no-fflags", false, "skip reapplying fflags and overlay files")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if CommRunning(b.Type.Executable()) {
+		return errors.New("roblox is running, refusing to repair")
+	}
+
+	// Dialogs and progress are unused, only the steps' results are shown.
+	b.Splash = splash.New(&splash.Config{})
+
+	var steps []RepairStep
+	if !*noPrefix {
+		steps = append(steps, RepairStep{"Wineprefix", repairPrefix})
+	}
+	if !*noWebView {
+		steps = append(steps, RepairStep{"WebView", repairWebView})
+	}
+	if !*noVerify {
+		steps = append(steps, RepairStep{"Installation", repairInstall})
+	}
+	if !*noFFlags {
+		steps = append(steps, RepairStep{"FFlags and overlay", repairFFlags})
+	}
+
+	for _, s := range steps {
+		res, err := s.Run(b)
+		if err != nil {
+			fmt.Printf("* %s: [FAIL] %s\n", s.Name, err)
+			return fmt.Errorf("repair %s: %w", s.Name, err)
+		}
+
+		fmt.Printf("* %s: [OK] %s\n", s.Name, res)
+	}
+
+	return b.GlobalState.Save()
+}
+
+func repairPrefix(b *Binary) (string, error) {
+	if _, err := os.Stat(filepath.Join(b.Prefix.Dir(), "drive_c", "windows")); err != nil {
+		if err := b.InitPrefix(); err != nil {
+			return "", err
+		}
+
+		if v, err := b.Prefix.Version(); err == nil {
+			b.State.WineVersion = v.String()
+		}
+		return "initialized missing wineprefix", nil
+	}
+
+	if err := b.Prefix.Update(); err != nil {
+		return "", err
+	}
+
+	v, err := b.Prefix.Version()
+	if err != nil {
+		return "", err
+	}
+
+	b.State.WineVersion = v.String()
+	return "updated with " + b.State.WineVersion, nil
+}
+
+func repairWebView(b *Binary) (string, error) {
+	if b.Config.SkipWebView {
+		return "skipped by configuration", nil
+	}
+
+	if b.State.WebView == WebViewVersion && b.WebViewInstalled() {
+		return "already installed", nil
+	}
+
+	if err := b.ReinitWebView(); err != nil {
+		return "", err
+	}
+
+	return "reinstalled " + WebViewVersion, nil
+}
+
+func repairInstall(b *Binary) (string, error) {
+	if err := b.FetchDeployment(); err != nil {
+		return "", err
+	}
+	b.Dir = filepath.Join(dirs.Versions, b.Deploy.GUID)
+
+	// Installing verifies the cached packages, only downloading
+	// those that are missing or corrupted.
+	downloaded, err := b.Install()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("verified %s, downloaded %d packages", b.Deploy.GUID, len(downloaded)), nil
+}
+
+func repairFFlags(b *Binary) (string, error) {
+	if b.Dir == "" {
+		if b.State.Version == "" {
+			return "", ErrNotInstalled
+		}
+		b.Dir = filepath.Join(dirs.Versions, b.State.Version)
+	}
+
+	if err := b.ApplyFFlags(); err != nil {
+		return "", err
+	}
+
+	if err := dirs.OverlayDir(b.Dir); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("applied %d fflags to %s", len(b.Config.FFlags), b.SettingsFile()), nil
+}
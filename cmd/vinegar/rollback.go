@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/state"
+)
+
+// Rollback switches the Binary to the newest previous version kept
+// after updating, which is used until a version newer than the one
+// rolled back from is available.
+func (b *Binary) Rollback() error {
+	if CommRunning(b.Type.Executable()) {
+		return errors.New("roblox is running, refusing to roll back")
+	}
+
+	if len(b.State.Previous) == 0 {
+		return state.ErrNoPrevious
+	}
+
+	// Checked before the state is modified by rolling back.
+	prev := b.State.Previous[0].GUID
+	exe := filepath.Join(dirs.Versions, prev, b.Type.Executable())
+	if _, err := os.Stat(exe); err != nil {
+		return fmt.Errorf("previous version %s: %w", prev, err)
+	}
+
+	cur := b.State.Version
+	d, err := b.State.Rollback()
+	if err != nil {
+		return err
+	}
+
+	if err := b.GlobalState.Save(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	fmt.Printf("Rolled back from %s to %s\n", cur, d)
+	return nil
+}
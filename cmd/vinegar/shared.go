@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	cp "github.com/otiai10/copy"
+	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/internal/state"
+)
+
+// A shared installation is a read-only directory, given by the
+// configuration's shared_dir, laid out like Vinegar's data directory:
+//
+//	versions/<guid>       Roblox version directories
+//	prefixes/player       Initialized Wineprefixes, such as
+//	prefixes/studio       after running Roblox once with them
+//
+// Each user's Wineprefix and version directory is an overlay of the
+// shared directory with fuse-overlayfs, with the user's changes kept in
+// the overlays directory within Vinegar's data directory. If overlays
+// are unavailable, the shared directories are copied instead.
+
+// SetupSharedPrefix makes the Binary's Wineprefix an overlay of the
+// shared installation's Wineprefix, if there is one and the Binary's
+// Wineprefix does not already exist.
+func (b *Binary) SetupSharedPrefix() error {
+	if b.GlobalConfig.SharedDir == "" {
+		return nil
+	}
+
+	dir := b.Prefix.Dir()
+	lower := filepath.Join(b.GlobalConfig.SharedDir, "prefixes", filepath.Base(dir))
+	if _, err := os.Stat(filepath.Join(lower, "drive_c")); err != nil {
+		slog.Warn("Shared installation has no wineprefix", "dir", lower)
+		return nil
+	}
+
+	if !mounted(dir) {
+		if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+			slog.Info("Using existing wineprefix over shared wineprefix", "dir", dir)
+			return nil
+		}
+	}
+
+	b.SetMessage("Setting up shared wineprefix")
+	return Overlay(lower, dir)
+}
+
+// SetupSharedVersion makes the Binary's version directory an overlay of
+// the shared installation's version directory of the Binary's deployment,
+// returning false if the shared installation does not have it.
+func (b *Binary) SetupSharedVersion() (bool, error) {
+	if b.GlobalConfig.SharedDir == "" {
+		return false, nil
+	}
+
+	// The previous version's overlay would otherwise be removed
+	// through it's mount point when cleaning up versions.
+	old := filepath.Join(dirs.Versions, b.State.Version)
+	if b.State.Version != b.Deploy.GUID && mounted(old) {
+		if err := unmount(old); err != nil {
+			return false, fmt.Errorf("unmount %s: %w", b.State.Version, err)
+		}
+	}
+
+	lower := filepath.Join(b.GlobalConfig.SharedDir, "versions", b.Deploy.GUID)
+	if _, err := os.Stat(filepath.Join(lower, b.Type.Executable())); err != nil {
+		slog.Warn("Shared installation does not have the deployment, installing",
+			"guid", b.Deploy.GUID)
+		return false, nil
+	}
+
+	b.SetMessage("Setting up shared " + b.Alias)
+	if err := Overlay(lower, b.Dir); err != nil {
+		return false, err
+	}
+
+	if b.State.Version != b.Deploy.GUID {
+		b.State.Version = b.Deploy.GUID
+		b.State.Packages = nil
+		b.State.Launches = 0
+		b.State.Deployment = &state.Deployment{
+			Channel:   b.Deploy.Channel,
+			GUID:      b.Deploy.GUID,
+			Installed: time.Now().UTC(),
+		}
+	}
+
+	return true, nil
+}
+
+// Overlay makes the read-only lower directory available at the named
+// directory, as an overlay mount with fuse-overlayfs, storing changes in
+// the overlays directory. If fuse-overlayfs is unavailable or fails, the
+// lower directory is copied to the named directory instead.
+func Overlay(lower, dir string) error {
+	if mounted(dir) {
+		return nil
+	}
+
+	upper := filepath.Join(overlayDir(dir), "upper")
+	work := filepath.Join(overlayDir(dir), "work")
+
+	if err := dirs.Mkdirs(dir, upper, work); err != nil {
+		return err
+	}
+
+	if _, err := exec.LookPath("fuse-overlayfs"); err == nil {
+		slog.Info("Mounting overlay", "lower", lower, "dir", dir)
+
+		// Squashing ownership is required as Wine refuses to use
+		// a Wineprefix not owned by the current user.
+		out, err := exec.Command("fuse-overlayfs", "-o", fmt.Sprintf(
+			"lowerdir=%s,upperdir=%s,workdir=%s,squash_to_uid=%d,squash_to_gid=%d",
+			lower, upper, work, os.Getuid(), os.Getgid()), dir).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+
+		slog.Warn("Failed to mount overlay, copying instead",
+			"error", err, "output", strings.TrimSpace(string(out)))
+	} else {
+		slog.Warn("fuse-overlayfs is not installed, copying shared directory", "lower", lower)
+	}
+
+	return cp.Copy(lower, dir, cp.Options{
+		PermissionControl: cp.AddPermission(0o200),
+	})
+}
+
+// overlayDir returns the directory holding the changes
+// made to the overlay mounted at the named directory.
+func overlayDir(dir string) string {
+	return filepath.Join(dirs.Data, "overlays",
+		filepath.Base(filepath.Dir(dir))+"-"+filepath.Base(dir))
+}
+
+// unmount unmounts the FUSE mount at the named directory.
+func unmount(dir string) error {
+	slog.Info("Unmounting overlay", "dir", dir)
+
+	fm, err := exec.LookPath("fusermount3")
+	if err != nil {
+		fm = "fusermount"
+	}
+
+	return exec.Command(fm, "-u", dir).Run()
+}
+
+// mounted determines if the named directory is a mount point.
+func mounted(dir string) bool {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		// Mount points have spaces and such escaped in octal.
+		if mp, err := strconv.Unquote(`"` + fields[4] + `"`); err == nil && mp == dir {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,27 +2,38 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"os"
 	"path"
 	"runtime/debug"
 
 	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/internal/state"
 	"github.com/vinegarhq/vinegar/roblox"
 	"github.com/vinegarhq/vinegar/sysinfo"
 	"github.com/vinegarhq/vinegar/wine"
 )
 
-func PrintSysinfo(cfg *config.Config) {
+func PrintSysinfo(cfg *config.Config) error {
+	return Sysinfo(os.Stdout, cfg)
+}
+
+// Sysinfo writes information about the system and the given
+// configuration's Wine installations to w.
+func Sysinfo(w io.Writer, cfg *config.Config) error {
 	playerPfx, err := wine.New(BinaryPrefixDir(roblox.Player), cfg.Player.WineRoot)
 	if err != nil {
-		log.Fatalf("player prefix: %s", err)
+		return fmt.Errorf("player prefix: %w", err)
 	}
 
 	studioPfx, err := wine.New(BinaryPrefixDir(roblox.Studio), cfg.Studio.WineRoot)
 	if err != nil {
-		log.Fatalf("studio prefix: %s", err)
+		return fmt.Errorf("studio prefix: %w", err)
 	}
 
+	// Errors are irrelevant, as the recorded versions will be empty.
+	s, _ := state.Load()
+
 	var revision string
 	bi, _ := debug.ReadBuildInfo()
 	for _, bs := range bi.Settings {
@@ -37,26 +48,51 @@ func PrintSysinfo(cfg *config.Config) {
   * Supports AVX: %t
   * Supports split lock detection: %t
 * Kernel: %s
-* Wine (Player): %s
-* Wine (Studio): %s
+* Session: %s
+* Wine (Player): %s (wineprefix: %s)
+* Wine (Studio): %s (wineprefix: %s)
+* DXVK (Player): %s, VKD3D-Proton: %s
+* DXVK (Studio): %s, VKD3D-Proton: %s
 `
 
-	fmt.Printf(info,
+	fmt.Fprintf(w, info,
 		Version, revision,
 		sysinfo.Distro,
 		sysinfo.CPU.Name,
 		sysinfo.CPU.AVX, sysinfo.CPU.SplitLockDetect,
 		sysinfo.Kernel,
-		playerPfx.Version(),
-		studioPfx.Version(),
+		sysinfo.Session,
+		wineVersion(playerPfx), s.Player.WineVersion,
+		wineVersion(studioPfx), s.Studio.WineVersion,
+		orNone(s.Player.DxvkVersion), orNone(s.Player.Vkd3d),
+		orNone(s.Studio.DxvkVersion), orNone(s.Studio.Vkd3d),
 	)
 
 	if sysinfo.InFlatpak {
-		fmt.Println("* Flatpak: [x]")
+		fmt.Fprintln(w, "* Flatpak: [x]")
 	}
 
-	fmt.Println("* Cards:")
+	fmt.Fprintln(w, "* Cards:")
 	for i, c := range sysinfo.Cards {
-		fmt.Printf("  * Card %d: %s %s %s\n", i, c.Driver, path.Base(c.Device), c.Path)
+		fmt.Fprintf(w, "  * Card %d: %s %s %s (%s:%s)\n", i, c.Driver, path.Base(c.Device), c.Path, c.Vendor, c.Product)
 	}
+
+	return nil
+}
+
+func wineVersion(pfx *wine.Prefix) string {
+	v, err := pfx.Version()
+	if err != nil {
+		return fmt.Sprintf("unknown (%s)", err)
+	}
+
+	return v.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
 }
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -15,22 +16,92 @@ import (
 )
 
 const (
+	WebViewVersion         = "109.0.1518.140"
 	WebViewInstallerURL    = "https://catalog.s.download.windowsupdate.com/c/msdownload/update/software/updt/2023/09/microsoftedgestandaloneinstallerx64_1c890b4b8dd6b7c93da98ebdc08ecdc5e30e50cb.exe"
 	WebViewTargetInstaller = "MicrosoftEdge_X64_109.0.1518.140.exe.{0D50BFEC-CD6A-4F9A-964C-C7416E3ACB10}"
 )
 
 var WebViewInstallerPath = filepath.Join(dirs.Cache, "MicrosoftEdge_X64_109.0.1518.140.exe")
 
+// SetupWebView installs WebView if the state does not mark the current
+// WebView version as installed in the Binary's Wineprefix.
+//
+// Wineprefixes initialized before the state marker existed are
+// marked as installed if the WebView installation is present.
+func (b *Binary) SetupWebView() error {
+	if b.State.WebView == WebViewVersion {
+		return nil
+	}
+
+	if b.State.WebView == "" && b.WebViewInstalled() {
+		slog.Info("Found existing WebView installation")
+
+		b.State.WebView = WebViewVersion
+		return b.GlobalState.Save()
+	}
+
+	if b.Config.WebViewBackground && !b.Config.SkipWebView {
+		slog.Warn("Deferring WebView installation until Roblox has launched, logging in from within Roblox will not work until it has finished!")
+		b.WebViewDeferred = true
+		return nil
+	}
+
+	return b.InstallWebView()
+}
+
+// BackgroundWebView installs WebView once Roblox has launched or exited, if
+// its installation was deferred by SetupWebView. The returned channel is
+// closed when the installation has finished, and is nil if not deferred.
+func (b *Binary) BackgroundWebView(launched, exited <-chan struct{}) <-chan struct{} {
+	if !b.WebViewDeferred {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		select {
+		case <-launched:
+		case <-exited:
+		}
+
+		slog.Info("Installing WebView in the background")
+		if err := b.InstallWebView(); err != nil {
+			slog.Error("Failed to install WebView", "error", err)
+			return
+		}
+
+		b.WebViewDeferred = false
+		slog.Info("Installed WebView in the background")
+	}()
+
+	return done
+}
+
+// WebViewInstalled determines if the current WebView version's
+// installation is present in the Binary's Wineprefix.
+func (b *Binary) WebViewInstalled() bool {
+	_, err := os.Stat(filepath.Join(b.Prefix.Dir(), "drive_c", "Program Files (x86)",
+		"Microsoft", "EdgeWebView", "Application", WebViewVersion))
+	return err == nil
+}
+
 func (b *Binary) InstallWebView() error {
+	if b.Config.SkipWebView {
+		slog.Warn("Skipping WebView installation, logging in from within Roblox will not work!")
+		return nil
+	}
+
 	// This is required for the installer to do some magic
 	// that makes it work.
 	slog.Info("Setting Wineprefix version to win7")
-	b.Splash.SetMessage("Setting up wineprefix")
+	b.SetMessage("Setting up wineprefix")
 	if err := b.Prefix.Wine("winecfg", "/v", "win7").Run(); err != nil {
 		return err
 	}
 
-	b.Splash.SetDesc("109.0.1518.140")
+	b.SetDesc(WebViewVersion)
 
 	if _, err := os.Stat(WebViewInstallerPath); err != nil {
 		if err := b.DownloadWebView(); err != nil {
@@ -38,16 +109,34 @@ func (b *Binary) InstallWebView() error {
 		}
 	}
 
-	b.Splash.SetMessage("Installing WebView")
+	b.SetMessage("Installing WebView")
 	slog.Info("Running WebView installer", "path", WebViewInstallerPath)
 
-	return b.Prefix.Wine(WebViewInstallerPath,
+	if err := b.Prefix.Wine(WebViewInstallerPath,
 		"--msedgewebview", "--do-not-launch-msedge", "--system-level",
-	).Run()
+	).Run(); err != nil {
+		return err
+	}
+
+	b.State.WebView = WebViewVersion
+	return b.GlobalState.Save()
+}
+
+// ReinitWebView resets the WebView state marker and installs WebView
+// again, to repair a broken in-app browser.
+func (b *Binary) ReinitWebView() error {
+	slog.Info("Reinitializing WebView", "old_version", b.State.WebView)
+
+	b.State.WebView = ""
+	if err := b.GlobalState.Save(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	return b.InstallWebView()
 }
 
 func (b *Binary) DownloadWebView() error {
-	b.Splash.SetMessage("Downloading WebView")
+	b.SetMessage("Downloading WebView")
 
 	tmp, err := os.CreateTemp("", "unc_msedgestandalone.*.exe")
 	if err != nil {
@@ -58,12 +147,12 @@ func (b *Binary) DownloadWebView() error {
 	slog.Info("Downloading WebView",
 		"version", "109.0.1518.140", "url", WebViewInstallerURL, "path", tmp.Name())
 
-	err = netutil.DownloadProgress(WebViewInstallerURL, tmp.Name(), b.Splash.SetProgress)
+	err = netutil.DownloadProgress(WebViewInstallerURL, tmp.Name(), b.SetProgress)
 	if err != nil {
 		return err
 	}
 
-	b.Splash.SetMessage("Extracting WebView")
+	b.SetMessage("Extracting WebView")
 	return GetWebViewInstaller(tmp)
 }
 
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// windowTimeout is how long to wait for the Roblox window to appear.
+const windowTimeout = 30 * time.Second
+
+// activationEnv is a list of environment variables holding the startup
+// notification and XDG activation tokens given by the launching
+// application, which compositors use to permit focusing new windows.
+var activationEnv = []string{"XDG_ACTIVATION_TOKEN", "DESKTOP_STARTUP_ID"}
+
+// SetWMClass sets the WM_CLASS of the Binary's window to the configured
+// WM class, using xdotool.
+//
+// Wine already names the WM_CLASS of it's windows after the executable in
+// lowercase, such as 'robloxplayerbeta.exe', but provides no way to change
+// it. Hence this only works under X11 or XWayland, and requires xdotool
+// to be installed; windows created by Wine's Wayland driver are unaffected.
+func (b *Binary) SetWMClass() {
+	if b.Config.WMClass == "" {
+		return
+	}
+
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		slog.Error("Cannot set Roblox WM_CLASS", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), windowTimeout)
+	defer cancel()
+
+	slog.Info("Setting Roblox WM_CLASS", "class", b.Config.WMClass)
+
+	cmd := exec.CommandContext(ctx, xdotool,
+		"search", "--sync", "--classname", strings.ToLower(b.Type.Executable()),
+		"set_window", "--class", b.Config.WMClass, "--classname", b.Config.WMClass,
+	)
+	if err := cmd.Run(); err != nil {
+		slog.Error("Failed to set Roblox WM_CLASS", "error", err)
+	}
+}
+
+// FocusWindow attempts to activate the Binary's existing window using
+// xdotool, which only works under X11 or XWayland.
+func (b *Binary) FocusWindow() error {
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		return err
+	}
+
+	return exec.Command(xdotool,
+		"search", "--classname", strings.ToLower(b.Type.Executable()), "windowactivate",
+	).Run()
+}
+
+// ActivateWindow focuses the Binary's window once it appears if
+// configured to, using xdotool. Like SetWMClass, this only works under
+// X11 or XWayland, and the compositor may still refuse activation.
+func (b *Binary) ActivateWindow() {
+	if b.Config.Focus != "activate" {
+		return
+	}
+
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		slog.Error("Cannot focus Roblox window", "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), windowTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, xdotool,
+		"search", "--sync", "--classname", strings.ToLower(b.Type.Executable()),
+		"windowactivate",
+	)
+	if err := cmd.Run(); err != nil {
+		slog.Error("Failed to focus Roblox window", "error", err)
+		return
+	}
+
+	slog.Info("Focused Roblox window")
+}
+
+// WaitWindow keeps the splash window shown until the Binary's window has
+// appeared, the configured window wait has passed or Roblox has exited, so
+// that Vinegar does not appear to have frozen while Roblox is starting.
+// Like SetWMClass, this requires xdotool, otherwise the splash is closed.
+func (b *Binary) WaitWindow(exited <-chan struct{}) {
+	defer b.Splash.Close()
+
+	if b.GlobalConfig.WindowWait == 0 {
+		return
+	}
+
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		slog.Warn("Cannot wait for the Roblox window", "error", err)
+		return
+	}
+
+	b.SetMessage("Waiting for Roblox...")
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(b.GlobalConfig.WindowWait)*time.Second)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-exited:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	_ = exec.CommandContext(ctx, xdotool,
+		"search", "--sync", "--classname", strings.ToLower(b.Type.Executable()),
+	).Run()
+}
+
+// WatchWindow waits for the Binary's window to appear within the
+// configured window timeout, warning and killing the given Roblox
+// process if configured to when it never does, as Roblox can silently
+// fail after starting. Like SetWMClass, this requires xdotool.
+func (b *Binary) WatchWindow(p *os.Process, exited <-chan struct{}) {
+	if b.GlobalConfig.WindowTimeout == 0 {
+		return
+	}
+
+	xdotool, err := exec.LookPath("xdotool")
+	if err != nil {
+		slog.Warn("Cannot watch for the Roblox window", "error", err)
+		return
+	}
+
+	timeout := time.Duration(b.GlobalConfig.WindowTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = exec.CommandContext(ctx, xdotool,
+		"search", "--sync", "--classname", strings.ToLower(b.Type.Executable()),
+	).Run()
+	if err == nil {
+		return
+	}
+
+	select {
+	case <-exited:
+		return
+	default:
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.Error("Failed to watch for the Roblox window", "error", err)
+		return
+	}
+
+	slog.Warn("Roblox has not created a window, it may have silently failed", "timeout", timeout)
+
+	if b.GlobalConfig.WindowTimeoutKill {
+		slog.Warn("Killing Roblox", "pid", p.Pid)
+		p.Kill()
+	}
+}
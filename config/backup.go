@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultBackupExclude is a list of patterns of the temporary and cache
+// directories within a Wineprefix, as well as Roblox installations made
+// by its own launcher, which are excluded from backups.
+var DefaultBackupExclude = []string{
+	"drive_c/windows/temp",
+	"drive_c/users/*/AppData/Local/Temp",
+	"drive_c/users/*/AppData/Local/Roblox/http",
+	"drive_c/users/*/AppData/Local/Roblox/rbx-storage",
+	"drive_c/users/*/AppData/Local/Roblox/logs",
+	"drive_c/users/*/AppData/Local/Roblox/Versions",
+	"drive_c/Program Files (x86)/Roblox/Versions",
+}
+
+// BackupExcluded determines if the given slash-separated path, relative
+// to a Wineprefix, matches or is within a path matching any of the
+// configuration's backup exclusion patterns.
+func (c *Config) BackupExcluded(name string) bool {
+	parts := strings.Split(path.Clean(name), "/")
+
+	for _, p := range c.BackupExclude {
+		n := strings.Count(path.Clean(p), "/") + 1
+		if n > len(parts) {
+			continue
+		}
+
+		if m, _ := path.Match(p, path.Join(parts[:n]...)); m {
+			return true
+		}
+	}
+
+	return false
+}
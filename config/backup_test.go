@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestBackupExcluded(t *testing.T) {
+	c := Config{BackupExclude: DefaultBackupExclude}
+
+	for name, want := range map[string]bool{
+		"drive_c/windows/temp":                                   true,
+		"drive_c/windows/temp/meow.log":                          true,
+		"drive_c/users/meow/AppData/Local/Roblox/http/a/b":       true,
+		"drive_c/users/meow/AppData/Local/Roblox/GlobalSettings": false,
+		"drive_c/windows":                                        false,
+		"drive_c/Program Files (x86)/Roblox/Versions/version-1":  true,
+		"user.reg": false,
+	} {
+		if got := c.BackupExcluded(name); got != want {
+			t.Errorf("BackupExcluded(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
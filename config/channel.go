@@ -0,0 +1,20 @@
+package config
+
+// ResolveChannel returns the deployment channel to use for a Binary,
+// which is the first non-empty channel in order of precedence:
+//
+//  1. cli, given on the command line
+//  2. uri, requested by Roblox in it's launch URI
+//  3. binary, the Binary's configured channel, including presets
+//  4. global, the configuration's default channel for all Binaries
+//
+// An empty channel is Roblox's default channel.
+func ResolveChannel(cli, uri, binary, global string) string {
+	for _, c := range []string{cli, uri, binary, global} {
+		if c != "" {
+			return c
+		}
+	}
+
+	return ""
+}
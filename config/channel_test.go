@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestResolveChannel(t *testing.T) {
+	tests := []struct {
+		cli, uri, binary, global string
+		want                     string
+	}{
+		{"", "", "", "", ""},
+		{"", "", "", "zglobal", "zglobal"},
+		{"", "", "zbinary", "zglobal", "zbinary"},
+		{"", "zuri", "zbinary", "zglobal", "zuri"},
+		{"zcli", "zuri", "zbinary", "zglobal", "zcli"},
+		{"zcli", "", "", "", "zcli"},
+	}
+
+	for _, tt := range tests {
+		if got := ResolveChannel(tt.cli, tt.uri, tt.binary, tt.global); got != tt.want {
+			t.Errorf("ResolveChannel(%q, %q, %q, %q) = %q, want %q",
+				tt.cli, tt.uri, tt.binary, tt.global, got, tt.want)
+		}
+	}
+}
@@ -4,13 +4,23 @@ package config
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
+	"github.com/vinegarhq/vinegar/internal/netutil"
 	"github.com/vinegarhq/vinegar/roblox"
 	"github.com/vinegarhq/vinegar/splash"
+	"github.com/vinegarhq/vinegar/sysinfo"
 	"github.com/vinegarhq/vinegar/wine"
 )
 
@@ -20,35 +30,132 @@ var LogoPath string
 
 // Config is a representation of a Roblox binary Vinegar configuration.
 type Binary struct {
-	Channel       string        `toml:"channel"`
-	Launcher      string        `toml:"launcher"`
-	Renderer      string        `toml:"renderer"`
-	WineRoot      string        `toml:"wineroot"`
-	DiscordRPC    bool          `toml:"discord_rpc"`
-	ForcedVersion string        `toml:"forced_version"`
-	Dxvk          bool          `toml:"dxvk"`
-	DxvkVersion   string        `toml:"dxvk_version"`
-	FFlags        roblox.FFlags `toml:"fflags"`
-	Env           Environment   `toml:"env"`
-	ForcedGpu     string        `toml:"gpu"`
-	GameMode      bool          `toml:"gamemode"`
+	Channel           string                   `toml:"channel"`
+	Launcher          string                   `toml:"launcher"`
+	WorkDir           string                   `toml:"workdir"` // Working directory of the Roblox process, Vinegar's if empty
+	Renderer          string                   `toml:"renderer"`
+	WineRoot          string                   `toml:"wineroot"`
+	Wineserver        string                   `toml:"wineserver"` // Path to the wineserver to use, the Wine installation's if empty
+	DiscordRPC        bool                     `toml:"discord_rpc"`
+	ForcedVersion     string                   `toml:"forced_version"`
+	Dxvk              bool                     `toml:"dxvk"`
+	DxvkVersion       string                   `toml:"dxvk_version"`
+	DxvkPath          string                   `toml:"dxvk_path"`  // DXVK release archive or directory to install instead of dxvk_version
+	Vkd3dPath         string                   `toml:"vkd3d_path"` // VKD3D-Proton release archive or directory to install, disabled if empty
+	FFlags            roblox.FFlags            `toml:"fflags"`
+	Env               Environment              `toml:"env"`
+	ForcedGpu         string                   `toml:"gpu"`
+	GameMode          bool                     `toml:"gamemode"`
+	GameModeScope     string                   `toml:"gamemode_scope"` // "roblox" to register only Roblox, "all" to also include Wine
+	Nice              int                      `toml:"nice"`           // Niceness of Roblox, from -20 to 19, unchanged if 0
+	IOClass           string                   `toml:"io_class"`       // I/O scheduling class of Roblox: realtime, best-effort or idle
+	IOPriority        int                      `toml:"io_priority"`    // I/O scheduling priority within io_class, from 0 (highest) to 7
+	WMClass           string                   `toml:"wm_class"`
+	Focus             string                   `toml:"focus"` // "activate" to focus the Roblox window once it appears, "none" to withhold activation tokens
+	Gamescope         Gamescope                `toml:"gamescope"`
+	PostUpdate        Hook                     `toml:"postupdate"`         // Run with the new version when one is installed
+	CookieFile        string                   `toml:"cookie_file"`        // Studio only, file holding the .ROBLOSECURITY cookie to log in with
+	CookieKeyring     bool                     `toml:"cookie_keyring"`     // Studio only, log in with the .ROBLOSECURITY cookie from the keyring
+	SkipWebView       bool                     `toml:"skip_webview"`       // In-app login will not work
+	WebViewBackground bool                     `toml:"webview_background"` // Install WebView after Roblox has launched, in-app login will not work until it has finished
+	SettingsFile      string                   `toml:"settings_file"`      // FFlags file, relative to the version directory, detected if empty
+	NoUpdater         bool                     `toml:"no_updater"`         // Prevent Roblox from updating itself, it may prompt to update instead
+	Presets           map[string]Preset        `toml:"presets"`
+	PlaceFFlags       map[string]roblox.FFlags `toml:"place_fflags"` // FFlags applied over fflags when joining the experience with the place ID
 }
 
 // Config is a representation of the Vinegar configuration.
 type Config struct {
-	MultipleInstances bool        `toml:"multiple_instances"`
-	SanitizeEnv       bool        `toml:"sanitize_env"`
-	Player            Binary      `toml:"player"`
-	Studio            Binary      `toml:"studio"`
-	Env               Environment `toml:"env"`
+	Channel            string      `toml:"channel"` // Default channel of Binaries without a configured channel
+	MultipleInstances  bool        `toml:"multiple_instances"`
+	SanitizeEnv        bool        `toml:"sanitize_env"`
+	EnvAllowlist       []string    `toml:"env_allowlist"` // Host environment variable patterns passed to Wine, all others are removed if set
+	SteamEnv           []string    `toml:"steam_env"`     // Steam environment variable patterns removed when launched by Steam without Proton
+	AllowUnsupportedFS bool        `toml:"allow_unsupported_fs"`
+	BackupExclude      []string    `toml:"backup_exclude"` // Patterns of paths relative to the wineprefix excluded from backups
+	NoAVX              string      `toml:"no_avx"`
+	KillGracePeriod    int         `toml:"kill_grace_period"` // Seconds to wait for Roblox to exit before killing it
+	LogRetention       int         `toml:"log_retention"`     // Days to keep log files for in 'vinegar gc', 0 keeps them forever
+	WindowTimeout      int         `toml:"window_timeout"`    // Seconds to wait for the Roblox window after launch, 0 disables
+	WindowTimeoutKill  bool        `toml:"window_timeout_kill"`
+	WindowWait         int         `toml:"window_wait"`      // Seconds to keep the splash shown until the Roblox window appears, 0 disables
+	BrowserLogin       bool        `toml:"browser_login"`    // Open the login page in the browser when WebView is broken
+	VerifyInterval     int         `toml:"verify_interval"`  // Launches between full installation verifications, 0 disables
+	ChannelChange      string      `toml:"channel_change"`   // Whether to reinstall on channel changes, "reinstall" or "reuse"
+	Journal            bool        `toml:"journal"`          // Also send Vinegar's and Roblox's logs to the systemd journal
+	WineMismatch       string      `toml:"wine_mismatch"`    // What to do when Wine's version changes, "update", "warn" or "fail"
+	ExtractThreads     int         `toml:"extract_threads"`  // Packages extracted at once, picked from the CPU and disk if 0
+	DownloadThreads    int         `toml:"download_threads"` // Packages downloaded at once, 0 is unlimited
+	KeepPrevious       int         `toml:"keep_previous"`    // Previous versions kept after updating to roll back to, 0 disables
+	WineLog            bool        `toml:"wine_log"`         // Log Wine's output to a separate log file
+	LogDiscovery       string      `toml:"log_discovery"`    // How to find Roblox's log file, "auto", "fsnotify" or "poll"
+	Sync               string      `toml:"sync"`             // Wine synchronization primitive, "auto", "fsync", "esync" or "none", the environment's if empty
+	SharedDir          string      `toml:"shared_dir"`       // Read-only shared installation to overlay the versions and wineprefixes of
+	ExportActivity     bool        `toml:"export_activity"`  // Write the game activity as JSON to the runtime directory and events socket
+	StudioIsolation    bool        `toml:"studio_isolation"` // Run additional Studio instances with their own settings and autosaves
+	RegistryCheck      bool        `toml:"registry_check"`   // Check the wineprefix registry for corruption before launching
+	GPUProbe           bool        `toml:"gpu_probe"`        // Check that the graphics stack works before launching
+	Player             Binary      `toml:"player"`
+	Studio             Binary      `toml:"studio"`
+	Env                Environment `toml:"env"`
+
+	// CACertificates is a list of PEM encoded certificate files to trust in
+	// addition to the system's certificates, for use behind TLS-intercepting
+	// proxies. Any party holding the private key of these certificates
+	// can read and modify Vinegar's downloads, including Roblox itself.
+	CACertificates []string `toml:"ca_certificates"`
+
+	// UserAgent and Headers are sent with all of Vinegar's HTTP requests,
+	// for networks that block unknown user agents or require headers.
+	UserAgent string            `toml:"user_agent"`
+	Headers   map[string]string `toml:"headers"`
+
+	// Mirrors is a list of Roblox deploy mirror URLs, tried in order for
+	// every request, falling back to the next mirror if one fails.
+	// Vinegar's own list of mirrors is used if empty.
+	Mirrors []string `toml:"mirrors"`
+
+	EnvProfiles map[string]Environment `toml:"env_profiles"`
+
+	// SessionEnv holds environment variables applied only under the named
+	// display session type, "x11" or "wayland", as detected by sysinfo.
+	// No hints are set by default.
+	SessionEnv map[string]Environment `toml:"session_env"`
 
 	Splash splash.Config `toml:"splash"`
 }
 
 var (
-	ErrNeedDXVKRenderer = errors.New("dxvk is only valid with d3d renderers")
-	ErrWineRootAbs      = errors.New("wine root path is not an absolute path")
-	ErrWineRootInvalid  = errors.New("no wine binary present in wine root")
+	ErrNeedDXVKRenderer   = errors.New("dxvk is only valid with d3d renderers")
+	ErrWineRootAbs        = errors.New("wine root path is not an absolute path")
+	ErrWineRootInvalid    = errors.New("no wine binary present in wine root")
+	ErrWineserverAbs      = errors.New("wineserver path is not an absolute path")
+	ErrLauncherRecursive  = errors.New("launcher must not launch vinegar itself")
+	ErrBadWorkDir         = errors.New("workdir is not a directory")
+	ErrBadNoAVX           = errors.New("no_avx must be either ask, continue or fail")
+	ErrBadGracePeriod     = errors.New("kill grace period cannot be negative")
+	ErrBadLogRetention    = errors.New("log retention cannot be negative")
+	ErrBadWindowTimeout   = errors.New("window timeout cannot be negative")
+	ErrBadHeader          = errors.New("invalid http header")
+	ErrBadMirror          = errors.New("mirror must be an http or https url")
+	ErrUnknownKeys        = errors.New("unknown configuration keys")
+	ErrBadVerifyInterval  = errors.New("verify interval cannot be negative")
+	ErrBadChannelChange   = errors.New("channel_change must be either reinstall or reuse")
+	ErrBadForcedVersion   = errors.New("forced_version must be a version GUID, such as version-0123456789abcdef")
+	ErrBadWineMismatch    = errors.New("wine_mismatch must be either update, warn or fail")
+	ErrBadLogDiscovery    = errors.New("log_discovery must be either auto, fsnotify or poll")
+	ErrBadSync            = errors.New("sync must be either auto, fsync, esync or none")
+	ErrBadExtractThreads  = errors.New("extract threads cannot be negative")
+	ErrBadKeepPrevious    = errors.New("keep_previous cannot be negative")
+	ErrBadDownloadThreads = errors.New("download threads cannot be negative")
+	ErrBadGameModeScope   = errors.New("gamemode_scope must be either roblox or all")
+	ErrBadPlaceFFlags     = errors.New("place_fflags must be keyed by place IDs")
+	ErrBadFocus           = errors.New("focus must be either activate or none")
+	ErrCookieStudio       = errors.New("cookie injection is only supported by studio")
+	ErrCookieSources      = errors.New("cookie_file and cookie_keyring are mutually exclusive")
+	ErrBadNice            = errors.New("nice must be within -20 and 19")
+	ErrBadIOClass         = errors.New("io_class must be either realtime, best-effort or idle")
+	ErrBadIOPriority      = errors.New("io_priority must be within 0 and 7")
 )
 
 // Load will load the named file to a Config; if it doesn't exist, it
@@ -57,25 +164,52 @@ var (
 // The returned configuration will always be appended ontop of the default
 // configuration.
 //
+// Fields may be overriden with environment variables after the file is
+// loaded, refer to [EnvName] for how they are named.
+//
 // Load is required for any initialization for Config, as it calls routines
 // to setup certain variables and verifies the configuration.
 func Load(name string) (Config, error) {
 	cfg := Default()
 
-	if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
-		return cfg, nil
+	_, err := os.Stat(name)
+	exists := !errors.Is(err, os.ErrNotExist)
+
+	if exists {
+		if _, err := toml.DecodeFile(name, &cfg); err != nil {
+			return cfg, err
+		}
 	}
 
-	if _, err := toml.DecodeFile(name, &cfg); err != nil {
+	n, err := cfg.applyEnv()
+	if err != nil {
 		return cfg, err
 	}
 
+	if !exists && n == 0 {
+		return cfg, nil
+	}
+
 	return cfg, cfg.setup()
 }
 
 // Default returns a sane default configuration for Vinegar.
 func Default() Config {
 	return Config{
+		NoAVX:           "ask",
+		KillGracePeriod: 5,
+		LogRetention:    7,
+		VerifyInterval:  10,
+		ChannelChange:   "reinstall",
+		WineMismatch:    "update",
+		LogDiscovery:    "auto",
+		GPUProbe:        true,
+		RegistryCheck:   true,
+		WindowWait:      15,
+		KeepPrevious:    1,
+		DownloadThreads: 4,
+		BackupExclude:   DefaultBackupExclude,
+		SteamEnv:        DefaultSteamEnv,
 		Env: Environment{
 			"WINEARCH":                    "win64",
 			"WINEDEBUG":                   "err-kerberos,err-ntlm",
@@ -88,13 +222,14 @@ func Default() Config {
 		},
 
 		Player: Binary{
-			Dxvk:        true,
-			DxvkVersion: "2.3",
-			GameMode:    true,
-			ForcedGpu:   "prime-discrete",
-			Renderer:    "D3D11",
-			Channel:     "", // Default upstream
-			DiscordRPC:  true,
+			Dxvk:          true,
+			DxvkVersion:   "2.3",
+			GameMode:      true,
+			GameModeScope: "roblox",
+			ForcedGpu:     "prime-discrete",
+			Renderer:      "D3D11",
+			Channel:       "", // Default upstream
+			DiscordRPC:    true,
 			FFlags: roblox.FFlags{
 				"DFIntTaskSchedulerTargetFps": 640,
 			},
@@ -103,12 +238,13 @@ func Default() Config {
 			},
 		},
 		Studio: Binary{
-			Dxvk:        true,
-			DxvkVersion: "2.3",
-			GameMode:    true,
-			Channel:     "", // Default upstream
-			ForcedGpu:   "prime-discrete",
-			Renderer:    "D3D11",
+			Dxvk:          true,
+			DxvkVersion:   "2.3",
+			GameMode:      true,
+			GameModeScope: "roblox",
+			Channel:       "", // Default upstream
+			ForcedGpu:     "prime-discrete",
+			Renderer:      "D3D11",
 			// TODO: fill with studio fflag/env goodies
 			FFlags: make(roblox.FFlags),
 			Env:    make(Environment),
@@ -123,6 +259,7 @@ func Default() Config {
 			TrackColor:  0x303030,
 			AccentColor: 0x8fbc5e,
 			InfoColor:   0x777777,
+			Position:    "center",
 		},
 	}
 }
@@ -131,15 +268,61 @@ func (b *Binary) LauncherPath() (string, error) {
 	return exec.LookPath(strings.Fields(b.Launcher)[0])
 }
 
+// CheckLauncher returns [ErrLauncherRecursive] if the launcher's
+// executable resolves to Vinegar, which would have Vinegar
+// endlessly launch itself instead of Roblox.
+func (b *Binary) CheckLauncher() error {
+	if strings.TrimSpace(b.Launcher) == "" {
+		return nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	self, err = filepath.EvalSymlinks(self)
+	if err != nil {
+		return nil
+	}
+
+	p, err := b.LauncherPath()
+	if err != nil {
+		return nil
+	}
+
+	if p, err = filepath.EvalSymlinks(p); err == nil && p == self {
+		return fmt.Errorf("%w: %s", ErrLauncherRecursive, b.Launcher)
+	}
+
+	return nil
+}
+
+// versionGUID matches Roblox deployment version GUIDs.
+var versionGUID = regexp.MustCompile(`^version-[0-9a-f]{16}$`)
+
 func (b *Binary) validate() error {
 	if !strings.HasPrefix(b.Renderer, "D3D11") && b.Dxvk {
 		return ErrNeedDXVKRenderer
 	}
 
+	if b.ForcedVersion != "" && !versionGUID.MatchString(b.ForcedVersion) {
+		return fmt.Errorf("%w: %q", ErrBadForcedVersion, b.ForcedVersion)
+	}
+
 	if b.Launcher != "" {
 		if _, err := b.LauncherPath(); err != nil {
 			return fmt.Errorf("bad launcher: %w", err)
 		}
+
+		if err := b.CheckLauncher(); err != nil {
+			return err
+		}
+	}
+
+	if b.WorkDir != "" {
+		if fi, err := os.Stat(b.WorkDir); err != nil || !fi.IsDir() {
+			return fmt.Errorf("%w: %s", ErrBadWorkDir, b.WorkDir)
+		}
 	}
 
 	if b.WineRoot != "" {
@@ -148,9 +331,89 @@ func (b *Binary) validate() error {
 		}
 	}
 
+	if b.Wineserver != "" {
+		if !filepath.IsAbs(b.Wineserver) {
+			return fmt.Errorf("bad wineserver: %w", ErrWineserverAbs)
+		}
+
+		if _, err := exec.LookPath(b.Wineserver); err != nil {
+			return fmt.Errorf("bad wineserver: %w", err)
+		}
+	}
+
+	if err := b.PostUpdate.validate(); err != nil {
+		return fmt.Errorf("postupdate %w", err)
+	}
+
+	if err := b.Gamescope.validate(); err != nil {
+		return err
+	}
+
+	switch b.GameModeScope {
+	case "", "roblox", "all":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadGameModeScope, b.GameModeScope)
+	}
+
+	switch b.Focus {
+	case "", "activate", "none":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadFocus, b.Focus)
+	}
+
+	if b.Nice < -20 || b.Nice > 19 {
+		return fmt.Errorf("%w: %d", ErrBadNice, b.Nice)
+	}
+
+	switch b.IOClass {
+	case "", "realtime", "best-effort", "idle":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadIOClass, b.IOClass)
+	}
+
+	if b.IOPriority < 0 || b.IOPriority > 7 {
+		return fmt.Errorf("%w: %d", ErrBadIOPriority, b.IOPriority)
+	}
+
+	for name := range b.Presets {
+		if !validPresetName(name) {
+			return fmt.Errorf("%w: %q", ErrBadPresetName, name)
+		}
+	}
+
+	if err := b.FFlags.Validate(); err != nil {
+		return err
+	}
+
+	for place, ff := range b.PlaceFFlags {
+		if id, err := strconv.ParseUint(place, 10, 64); err != nil || id == 0 {
+			return fmt.Errorf("%w: %q", ErrBadPlaceFFlags, place)
+		}
+
+		if err := ff.Validate(); err != nil {
+			return fmt.Errorf("place %s: %w", place, err)
+		}
+	}
+
 	return nil
 }
 
+// FFlagsFor returns the Binary's FFlags with the FFlags of the given
+// place ID applied over them, or only the Binary's FFlags if the place
+// has none configured.
+func (b *Binary) FFlagsFor(place string) roblox.FFlags {
+	pf, ok := b.PlaceFFlags[place]
+	if !ok {
+		return b.FFlags
+	}
+
+	ff := make(roblox.FFlags, len(b.FFlags)+len(pf))
+	maps.Copy(ff, b.FFlags)
+	maps.Copy(ff, pf)
+
+	return ff
+}
+
 func (b *Binary) setup() error {
 	if err := b.validate(); err != nil {
 		return fmt.Errorf("invalid: %w", err)
@@ -163,13 +426,173 @@ func (b *Binary) setup() error {
 	return b.pickCard()
 }
 
+// ValidateFile decodes the named file ontop of the default configuration
+// and validates it with [Config.Validate], without applying it. Unlike
+// Load, the file must exist, keys unknown to Vinegar are considered
+// an error and environment variable overrides are ignored.
+func ValidateFile(name string) error {
+	cfg := Default()
+
+	md, err := toml.DecodeFile(name, &cfg)
+	if err != nil {
+		return err
+	}
+
+	if keys := md.Undecoded(); len(keys) > 0 {
+		return fmt.Errorf("%w: %v", ErrUnknownKeys, keys)
+	}
+
+	return cfg.Validate()
+}
+
+// Validate checks the configuration for errors, without modifying
+// it or applying it to the environment.
+func (c *Config) Validate() error {
+	if c.Player.CookieFile != "" || c.Player.CookieKeyring {
+		return fmt.Errorf("player: %w", ErrCookieStudio)
+	}
+
+	if c.Studio.CookieFile != "" && c.Studio.CookieKeyring {
+		return fmt.Errorf("studio: %w", ErrCookieSources)
+	}
+
+	switch c.NoAVX {
+	case "ask", "continue", "fail":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadNoAVX, c.NoAVX)
+	}
+
+	if c.KillGracePeriod < 0 {
+		return ErrBadGracePeriod
+	}
+
+	if c.LogRetention < 0 {
+		return ErrBadLogRetention
+	}
+
+	if c.WindowTimeout < 0 || c.WindowWait < 0 {
+		return ErrBadWindowTimeout
+	}
+
+	if c.VerifyInterval < 0 {
+		return ErrBadVerifyInterval
+	}
+
+	switch c.ChannelChange {
+	case "reinstall", "reuse":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadChannelChange, c.ChannelChange)
+	}
+
+	switch c.WineMismatch {
+	case "update", "warn", "fail":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadWineMismatch, c.WineMismatch)
+	}
+
+	if c.ExtractThreads < 0 {
+		return ErrBadExtractThreads
+	}
+
+	if c.KeepPrevious < 0 {
+		return ErrBadKeepPrevious
+	}
+
+	if c.DownloadThreads < 0 {
+		return ErrBadDownloadThreads
+	}
+
+	switch c.LogDiscovery {
+	case "auto", "fsnotify", "poll":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadLogDiscovery, c.LogDiscovery)
+	}
+
+	switch c.Sync {
+	case "", "auto", "fsync", "esync", "none":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadSync, c.Sync)
+	}
+
+	for _, p := range c.EnvAllowlist {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("env_allowlist: %w: %s", err, p)
+		}
+	}
+
+	for _, p := range c.BackupExclude {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("backup_exclude: %w: %s", err, p)
+		}
+	}
+
+	for _, p := range c.SteamEnv {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("steam_env: %w: %s", err, p)
+		}
+	}
+
+	if _, err := netutil.CertPool(c.CACertificates...); err != nil {
+		return fmt.Errorf("ca certificates: %w", err)
+	}
+
+	if strings.ContainsAny(c.UserAgent, "\r\n") {
+		return fmt.Errorf("%w: user agent contains a newline", ErrBadHeader)
+	}
+
+	for k, v := range c.Headers {
+		if !validHeaderName(k) || strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("%w: %q", ErrBadHeader, k)
+		}
+	}
+
+	for _, m := range c.Mirrors {
+		u, err := url.Parse(m)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %s", ErrBadMirror, m)
+		}
+	}
+
+	if err := c.Splash.Validate(); err != nil {
+		return fmt.Errorf("splash: %w", err)
+	}
+
+	for name, b := range map[string]Binary{"player": c.Player, "studio": c.Studio} {
+		// Binary setup modifies the Binary's FFlags and environment.
+		ff, env := b.FFlags, b.Env
+		b.FFlags = make(roblox.FFlags, len(ff))
+		maps.Copy(b.FFlags, ff)
+		b.Env = make(Environment, len(env))
+		maps.Copy(b.Env, env)
+
+		if err := b.setup(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
 func (c *Config) setup() error {
-	if c.SanitizeEnv {
-		SanitizeEnv()
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	switch {
+	case c.SanitizeEnv:
+		SanitizeEnv(c.EnvAllowlist...)
+	case len(c.EnvAllowlist) > 0:
+		slog.Info("Applying environment allowlist", "allowlist", c.EnvAllowlist)
+		FilterEnv(c.EnvAllowlist...)
 	}
 
 	c.Env.Setenv()
 
+	if e, ok := c.SessionEnv[sysinfo.Session]; ok {
+		slog.Info("Applying session environment", "session", sysinfo.Session)
+		e.Setenv()
+	}
+
 	if err := c.Player.setup(); err != nil {
 		return fmt.Errorf("player: %w", err)
 	}
@@ -180,3 +603,20 @@ func (c *Config) setup() error {
 
 	return nil
 }
+
+// validHeaderName reports whether name is a valid HTTP header field
+// name, which is a non-empty RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) ||
+			strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/vinegarhq/vinegar/roblox"
@@ -37,6 +38,21 @@ func TestBinarySetup(t *testing.T) {
 		t.Error("expected not dxvk appropiate renderer check")
 	}
 
+	b.ForcedVersion = "0123456789abcdef"
+	if err := b.setup(); !errors.Is(err, ErrBadForcedVersion) {
+		t.Error("expected forced version check")
+	}
+
+	b.ForcedVersion = "version-meow"
+	if err := b.setup(); !errors.Is(err, ErrBadForcedVersion) {
+		t.Error("expected forced version guid check")
+	}
+
+	b.ForcedVersion = "version-0123456789abcdef"
+	if err := b.setup(); errors.Is(err, ErrBadForcedVersion) {
+		t.Error("expected valid forced version")
+	}
+
 	if os.Getenv("MEOW") == "MEOW" {
 		t.Error("expected no change in environment")
 	}
@@ -45,4 +61,84 @@ func TestBinarySetup(t *testing.T) {
 	if err := b.setup(); !errors.Is(err, exec.ErrNotFound) {
 		t.Error("expected exec not found")
 	}
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Launcher = self + " --meow"
+	if err := b.CheckLauncher(); !errors.Is(err, ErrLauncherRecursive) {
+		t.Error("expected recursive launcher check")
+	}
+
+	b.Launcher = "env --config vinegar-gm.ini"
+	if err := b.CheckLauncher(); err != nil {
+		t.Errorf("expected launcher arguments to be ignored: %v", err)
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	c := Default()
+
+	t.Setenv("VINEGAR_PLAYER_CHANNEL", "zcanary")
+	t.Setenv("VINEGAR_MULTIPLE_INSTANCES", "true")
+	t.Setenv("VINEGAR_SPLASH_BACKGROUND", "0x000000")
+
+	n, err := c.applyEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Fatalf("want 3 overriden fields, got %d", n)
+	}
+
+	if c.Player.Channel != "zcanary" || !c.MultipleInstances || c.Splash.BgColor != 0 {
+		t.Fatal("expected environment to override configuration")
+	}
+
+	t.Setenv("VINEGAR_STUDIO_DXVK", "meow")
+	if _, err := c.applyEnv(); err == nil {
+		t.Fatal("expected invalid boolean to fail")
+	}
+}
+
+func TestValidHeaderName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"X-Forwarded-For": true,
+		"User-Agent":      true,
+		"":                false,
+		"Bad Header":      false,
+		"Bad:Header":      false,
+	} {
+		if validHeaderName(name) != valid {
+			t.Errorf("header %q: expected valid=%t", name, valid)
+		}
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config.toml")
+
+	if err := os.WriteFile(name, []byte("kill_grace_period = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateFile(name); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(name, []byte("kill_grace_priod = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateFile(name); !errors.Is(err, ErrUnknownKeys) {
+		t.Error("expected unknown key check")
+	}
+
+	if err := os.WriteFile(name, []byte("kill_grace_period = -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateFile(name); !errors.Is(err, ErrBadGracePeriod) {
+		t.Error("expected grace period check")
+	}
 }
@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vinegarhq/vinegar/config"
+	"github.com/vinegarhq/vinegar/sysinfo"
+	"github.com/vinegarhq/vinegar/wine"
+)
+
+var channelPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+type wizard struct {
+	s   *bufio.Scanner
+	out io.Writer
+}
+
+// ask prompts the question until validate accepts the answer,
+// using def as the answer if nothing was given.
+func (w *wizard) ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+
+		if !w.s.Scan() {
+			if err := w.s.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+
+		a := strings.TrimSpace(w.s.Text())
+		if a == "" {
+			a = def
+		}
+
+		if err := validate(a); err != nil {
+			fmt.Fprintln(w.out, "Invalid answer:", err)
+			continue
+		}
+
+		return a, nil
+	}
+}
+
+// Wizard asks the user on in and out for the most common configuration
+// options, validating each answer, and writes them as a configuration
+// file to the named path.
+func Wizard(name string, in io.Reader, out io.Writer) error {
+	w := wizard{s: bufio.NewScanner(in), out: out}
+
+	if _, err := os.Stat(name); err == nil {
+		a, err := w.ask(name+" already exists, overwrite? (y/n)", "n", validateYesNo)
+		if err != nil {
+			return err
+		}
+
+		if a != "y" {
+			return nil
+		}
+	}
+
+	root, err := w.ask("Wine installation path, empty for the system's Wine", "", func(a string) error {
+		_, err := wine.Wine64(a)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	channel, err := w.ask("Roblox Player channel, empty for the default channel", "", func(a string) error {
+		if !channelPattern.MatchString(a) {
+			return errors.New("channel must only contain letters, digits, '-' and '_'")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Available GPUs:")
+	for _, c := range sysinfo.Cards {
+		fmt.Fprintf(out, "  %s (embedded: %t)\n", c, c.Embedded)
+	}
+
+	gpu, err := w.ask("GPU to use, either integrated, prime-discrete or a GPU index", "prime-discrete", validateGpu)
+	if err != nil {
+		return err
+	}
+
+	rpc, err := w.ask("Enable Discord Rich Presence? (y/n)", "y", validateYesNo)
+	if err != nil {
+		return err
+	}
+
+	binary := map[string]any{
+		"wineroot": root,
+		"gpu":      gpu,
+	}
+	player := map[string]any{
+		"channel":     channel,
+		"discord_rpc": rpc == "y",
+	}
+	for k, v := range binary {
+		player[k] = v
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("# Generated by 'vinegar setup', see how to configure Vinegar on the documentation website:\n" +
+		"# https://vinegarhq.org/Configuration\n\n"); err != nil {
+		return err
+	}
+
+	if err := toml.NewEncoder(f).Encode(map[string]any{
+		"player": player,
+		"studio": binary,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := config.Load(name); err != nil {
+		return fmt.Errorf("generated configuration is invalid: %w", err)
+	}
+
+	fmt.Fprintln(out, "Wrote configuration to", name)
+	return nil
+}
+
+func validateYesNo(a string) error {
+	if a != "y" && a != "n" {
+		return errors.New("answer must be either y or n")
+	}
+	return nil
+}
+
+func validateGpu(a string) error {
+	if a == "integrated" || a == "prime-discrete" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(a)
+	if err != nil {
+		return err
+	}
+
+	if i < 0 || i >= len(sysinfo.Cards) {
+		return fmt.Errorf("no gpu with index %d", i)
+	}
+
+	return nil
+}
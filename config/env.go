@@ -1,10 +1,15 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path"
 	"strings"
 )
 
+var ErrNoEnvProfile = errors.New("environment profile not found")
+
 // Environment is a map representation of a operating environment
 // with it's variables.
 type Environment map[string]string
@@ -26,6 +31,17 @@ func (e Environment) Setenv() {
 	}
 }
 
+// EnvProfile returns the named environment profile, which is
+// meant to be merged over a Binary's environment.
+func (c *Config) EnvProfile(name string) (Environment, error) {
+	e, ok := c.EnvProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoEnvProfile, name)
+	}
+
+	return e, nil
+}
+
 var AllowedEnv = []string{
 	"PATH",
 	"HOME", "USER", "LOGNAME",
@@ -42,26 +58,75 @@ var AllowedEnv = []string{
 	"GAMEID", "STORE", "PROTONPATH",       // Required for ULWGL
 }
 
-// SanitizeEnv modifies the global environment by removing
-// all environment variables that are not present in [AllowedEnv].
-func SanitizeEnv() {
-	for _, env := range os.Environ() {
-		parts := strings.SplitN(env, "=", 2)
+// SanitizeEnv modifies the global environment by removing all
+// environment variables that are not present in [AllowedEnv]
+// or matched by the given allowlist, as described in [FilterEnv].
+func SanitizeEnv(allowlist ...string) {
+	FilterEnv(append(allowlist, AllowedEnv...)...)
+}
 
-		if len(parts) != 2 {
+// FilterEnv modifies the global environment by removing all environment
+// variables with names that are not matched by any of the given patterns,
+// in the syntax of [path.Match].
+func FilterEnv(patterns ...string) {
+	for _, env := range os.Environ() {
+		name, _, ok := strings.Cut(env, "=")
+		if !ok {
 			continue
 		}
 
 		allowed := false
-
-		for _, aenv := range AllowedEnv {
-			if aenv == parts[0] {
+		for _, p := range patterns {
+			if m, _ := path.Match(p, name); m {
 				allowed = true
+				break
 			}
 		}
 
 		if !allowed {
-			os.Unsetenv(parts[0])
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// DefaultSteamEnv is a list of patterns of the environment variables set
+// by Steam and its runtime, which break Wine outside of Proton.
+var DefaultSteamEnv = []string{
+	"STEAM_COMPAT_*",
+	"STEAM_RUNTIME*",
+	"PRESSURE_VESSEL_*",
+	"LD_PRELOAD", // Steam overlay
+}
+
+// InSteam determines if Vinegar was launched by Steam,
+// such as when it was added as a non-Steam game.
+func InSteam() bool {
+	for _, name := range []string{"SteamGameId", "SteamAppId", "STEAM_COMPAT_DATA_PATH"} {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
 		}
 	}
+
+	return false
+}
+
+// ScrubEnv removes the environment variables whose names match
+// any of the given patterns, and returns the removed names.
+func ScrubEnv(patterns ...string) (scrubbed []string) {
+	for _, env := range os.Environ() {
+		name, _, ok := strings.Cut(env, "=")
+		if !ok {
+			continue
+		}
+
+		for _, p := range patterns {
+			if m, _ := path.Match(p, name); m {
+				os.Unsetenv(name)
+				scrubbed = append(scrubbed, name)
+				break
+			}
+		}
+	}
+
+	return
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"testing"
 )
@@ -37,3 +38,52 @@ func TestSanitizeEnv(t *testing.T) {
 		t.Fatal("want sanitized impostor var, got value")
 	}
 }
+
+func TestEnvProfile(t *testing.T) {
+	c := Config{
+		EnvProfiles: map[string]Environment{
+			"debug": {"WINEDEBUG": "+all"},
+		},
+	}
+
+	if e, err := c.EnvProfile("debug"); err != nil || e["WINEDEBUG"] != "+all" {
+		t.Fatal("expected debug profile")
+	}
+
+	if _, err := c.EnvProfile("meow"); !errors.Is(err, ErrNoEnvProfile) {
+		t.Fatal("expected missing profile check")
+	}
+}
+
+func TestFilterEnv(t *testing.T) {
+	e := Environment{
+		"LC_MEOW":  "purr",
+		"ALLOWED":  "im not impostor",
+		"IMPOSTOR": "im impostor",
+	}
+
+	e.Setenv()
+	FilterEnv("ALLOWED", "LC_*")
+
+	if os.Getenv("ALLOWED") != e["ALLOWED"] || os.Getenv("LC_MEOW") != e["LC_MEOW"] {
+		t.Fatal("want allowed vars, got sanitized")
+	}
+
+	if os.Getenv("IMPOSTOR") != "" {
+		t.Fatal("want sanitized impostor var, got value")
+	}
+}
+
+func TestScrubEnv(t *testing.T) {
+	t.Setenv("STEAM_COMPAT_DATA_PATH", "/meow")
+	t.Setenv("MEOW", "purr")
+
+	if !InSteam() {
+		t.Fatal("expected to be in steam")
+	}
+
+	scrubbed := ScrubEnv(DefaultSteamEnv...)
+	if len(scrubbed) < 1 || os.Getenv("STEAM_COMPAT_DATA_PATH") != "" || os.Getenv("MEOW") != "purr" {
+		t.Fatalf("unexpected scrubbed environment %v", scrubbed)
+	}
+}
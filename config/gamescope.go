@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+var ErrBadGamescopeSize = errors.New("gamescope width and height must both be set and positive")
+
+// Gamescope is a representation of a gamescope nested session's options.
+type Gamescope struct {
+	Enabled      bool `toml:"enabled"`
+	Width        int  `toml:"width"`         // Resolution Roblox renders at
+	Height       int  `toml:"height"`        // Resolution Roblox renders at
+	OutputWidth  int  `toml:"output_width"`  // Resolution of the gamescope window
+	OutputHeight int  `toml:"output_height"` // Resolution of the gamescope window
+	Fullscreen   bool `toml:"fullscreen"`
+	FSR          bool `toml:"fsr"` // Upscale with AMD FidelityFX Super Resolution
+}
+
+// Path returns the path to the gamescope executable.
+func (g *Gamescope) Path() (string, error) {
+	return exec.LookPath("gamescope")
+}
+
+// Args returns the arguments to gamescope for the Gamescope's options,
+// excluding the '--' separator before the command to run.
+func (g *Gamescope) Args() (args []string) {
+	if g.Width > 0 && g.Height > 0 {
+		args = append(args, "-w", strconv.Itoa(g.Width), "-h", strconv.Itoa(g.Height))
+	}
+
+	if g.OutputWidth > 0 && g.OutputHeight > 0 {
+		args = append(args, "-W", strconv.Itoa(g.OutputWidth), "-H", strconv.Itoa(g.OutputHeight))
+	}
+
+	if g.Fullscreen {
+		args = append(args, "-f")
+	}
+
+	if g.FSR {
+		args = append(args, "-F", "fsr")
+	}
+
+	return
+}
+
+func (g *Gamescope) validate() error {
+	if !g.Enabled {
+		return nil
+	}
+
+	for _, s := range [][2]int{{g.Width, g.Height}, {g.OutputWidth, g.OutputHeight}} {
+		if s[0] < 0 || s[1] < 0 || (s[0] == 0) != (s[1] == 0) {
+			return fmt.Errorf("%w: %dx%d", ErrBadGamescopeSize, s[0], s[1])
+		}
+	}
+
+	if _, err := g.Path(); err != nil {
+		return fmt.Errorf("gamescope: %w", err)
+	}
+
+	return nil
+}
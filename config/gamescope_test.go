@@ -0,0 +1,27 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGamescopeArgs(t *testing.T) {
+	g := Gamescope{
+		Enabled:    true,
+		Width:      1280,
+		Height:     720,
+		Fullscreen: true,
+		FSR:        true,
+	}
+
+	want := []string{"-w", "1280", "-h", "720", "-f", "-F", "fsr"}
+	if args := g.Args(); !slices.Equal(args, want) {
+		t.Fatalf("gamescope args %v, want %v", args, want)
+	}
+
+	g.OutputWidth = 1920
+	if err := g.validate(); !errors.Is(err, ErrBadGamescopeSize) {
+		t.Fatal("expected partial output size check")
+	}
+}
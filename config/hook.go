@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Hook is a representation of a command run by Vinegar when an
+// event occurs, such as a new Roblox version being installed.
+type Hook struct {
+	Command  string `toml:"command"`
+	Required bool   `toml:"required"` // Fail the launch if the command fails
+}
+
+// Path returns the path to the Hook's command executable.
+func (h *Hook) Path() (string, error) {
+	return exec.LookPath(strings.Fields(h.Command)[0])
+}
+
+// Args returns the Hook command's arguments followed by the given arguments.
+func (h *Hook) Args(arg ...string) []string {
+	return append(strings.Fields(h.Command)[1:], arg...)
+}
+
+func (h *Hook) validate() error {
+	if strings.TrimSpace(h.Command) == "" {
+		return nil
+	}
+
+	if _, err := h.Path(); err != nil {
+		return fmt.Errorf("hook: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/vinegarhq/vinegar/roblox"
+)
+
+// fflagPattern matches the names of Roblox's FFlags, such as FFlagDebug
+// and DFIntTaskSchedulerTargetFps.
+var fflagPattern = regexp.MustCompile(`^(D|S)?F(Flag|Int|String|Log)[A-Za-z0-9_]+$`)
+
+// Import is a Binary configuration imported from another
+// bootstrapper's configuration by [ImportBootstrapper].
+type Import struct {
+	Channel    string        `toml:"channel,omitempty"`
+	Renderer   string        `toml:"renderer,omitempty"`
+	DiscordRPC *bool         `toml:"discord_rpc,omitempty"`
+	FFlags     roblox.FFlags `toml:"fflags,omitempty"`
+
+	// Unmapped holds the names of settings that have no
+	// equivalent in Vinegar's configuration.
+	Unmapped []string `toml:"-"`
+}
+
+// ImportBootstrapper translates the JSON configuration read from r into
+// a Binary configuration. Supported are Bloxstrap's FFlags file
+// (ClientAppSettings.json) and settings file (Settings.json), and
+// Sober's configuration file (config.json), which holds it's FFlags
+// within 'fflags'.
+func ImportBootstrapper(r io.Reader) (*Import, error) {
+	var settings map[string]any
+	if err := json.NewDecoder(r).Decode(&settings); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	imp := Import{FFlags: make(roblox.FFlags)}
+
+	for k, v := range settings {
+		if fflagPattern.MatchString(k) {
+			imp.FFlags[k] = fflagValue(v)
+			continue
+		}
+
+		if !imp.set(k, v) {
+			imp.Unmapped = append(imp.Unmapped, k)
+		}
+	}
+
+	sort.Strings(imp.Unmapped)
+	return &imp, nil
+}
+
+// fflagValue returns the decoded JSON FFlag value, with whole numbers,
+// which are decoded as floats, converted to integers.
+func fflagValue(v any) any {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return int64(f)
+	}
+
+	return v
+}
+
+// set applies the named Bloxstrap or Sober setting, returning
+// false if it has no equivalent or is of an unexpected type.
+func (imp *Import) set(name string, v any) bool {
+	switch name {
+	case "fflags": // Sober
+		ff, ok := v.(map[string]any)
+		for k, v := range ff {
+			imp.FFlags[k] = fflagValue(v)
+		}
+		return ok
+	case "UseDiscordRichPresence", "discord_rpc_enabled": // Bloxstrap, Sober
+		b, ok := v.(bool)
+		imp.DiscordRPC = &b
+		return ok
+	case "Channel": // Bloxstrap
+		c, ok := v.(string)
+		imp.Channel = c
+		return ok
+	case "use_opengl": // Sober
+		b, ok := v.(bool)
+		if b {
+			imp.Renderer = "OpenGL"
+		}
+		return ok
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportBootstrapper(t *testing.T) {
+	bloxstrap := `{
+		"DFIntTaskSchedulerTargetFps": "144",
+		"FFlagDebugGraphicsPreferVulkan": "True",
+		"UseDiscordRichPresence": false,
+		"BootstrapperStyle": 4
+	}`
+
+	imp, err := ImportBootstrapper(strings.NewReader(bloxstrap))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imp.FFlags["DFIntTaskSchedulerTargetFps"] != "144" || len(imp.FFlags) != 2 {
+		t.Errorf("expected bloxstrap fflags, got %v", imp.FFlags)
+	}
+
+	if imp.DiscordRPC == nil || *imp.DiscordRPC {
+		t.Error("expected discord rpc to be disabled")
+	}
+
+	if !slices.Equal(imp.Unmapped, []string{"BootstrapperStyle"}) {
+		t.Errorf("expected unmapped bootstrapper style, got %v", imp.Unmapped)
+	}
+
+	sober := `{"fflags": {"FFlagMeow": true}, "use_opengl": true, "server_location_indicator_enabled": true}`
+
+	imp, err = ImportBootstrapper(strings.NewReader(sober))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imp.FFlags["FFlagMeow"] != true || imp.Renderer != "OpenGL" {
+		t.Errorf("expected sober fflags and renderer, got %v %s", imp.FFlags, imp.Renderer)
+	}
+
+	if !slices.Equal(imp.Unmapped, []string{"server_location_indicator_enabled"}) {
+		t.Errorf("expected unmapped sober setting, got %v", imp.Unmapped)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables that override
+// configuration fields.
+const EnvPrefix = "VINEGAR_"
+
+// EnvName returns the name of the environment variable that overrides
+// the configuration field with the given TOML key path, such as
+// 'player.channel' becoming 'VINEGAR_PLAYER_CHANNEL'.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// applyEnv overrides the configuration's fields with the values of
+// their environment variables, as named by [EnvName], returning the
+// amount of fields that were overriden.
+//
+// Only fields with string, boolean and numeric types may be overriden;
+// tables such as 'env' and 'fflags' must be set in the configuration file.
+func (c *Config) applyEnv() (int, error) {
+	return applyEnv(reflect.ValueOf(c).Elem(), "")
+}
+
+func applyEnv(v reflect.Value, prefix string) (n int, err error) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		if key == "" || key == "-" || !f.IsExported() {
+			continue
+		}
+		key = prefix + key
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			sn, err := applyEnv(fv, key+".")
+			n += sn
+			if err != nil {
+				return n, err
+			}
+
+			continue
+		}
+
+		name := EnvName(key)
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setValue(fv, val); err != nil {
+			return n, fmt.Errorf("env %s: %w", name, err)
+		}
+
+		slog.Info("Overriding configuration from environment", "key", key, "env", name)
+		n++
+	}
+
+	return n, nil
+}
+
+// EnvOverrides returns the TOML key paths of the configuration fields
+// which are currently overriden by their environment variables.
+func EnvOverrides() (keys []string) {
+	return envOverrides(reflect.TypeOf(Config{}), "")
+}
+
+func envOverrides(t reflect.Type, prefix string) (keys []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		if key == "" || key == "-" || !f.IsExported() {
+			continue
+		}
+		key = prefix + key
+
+		if f.Type.Kind() == reflect.Struct {
+			keys = append(keys, envOverrides(f.Type, key+".")...)
+			continue
+		}
+
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return
+}
+
+func setValue(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
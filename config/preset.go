@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/vinegarhq/vinegar/roblox"
+)
+
+var (
+	ErrNoPreset      = errors.New("preset not found")
+	ErrBadPresetName = errors.New("preset names may only contain letters, digits, '-' and '_'")
+)
+
+// Preset is a named set of overrides for a Binary, used to quickly
+// switch between deployment channels.
+type Preset struct {
+	Channel string        `toml:"channel"`
+	FFlags  roblox.FFlags `toml:"fflags"`
+	Env     Environment   `toml:"env"`
+}
+
+// ApplyPreset merges the named preset's channel, FFlags and environment
+// over the Binary's configuration.
+func (b *Binary) ApplyPreset(name string) error {
+	p, ok := b.Presets[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoPreset, name)
+	}
+
+	if p.Channel != "" {
+		b.Channel = p.Channel
+	}
+
+	if b.FFlags == nil {
+		b.FFlags = make(roblox.FFlags)
+	}
+	maps.Copy(b.FFlags, p.FFlags)
+
+	if b.Env == nil {
+		b.Env = make(Environment)
+	}
+	maps.Copy(b.Env, p.Env)
+
+	return nil
+}
+
+func validPresetName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vinegarhq/vinegar/roblox"
+)
+
+func TestApplyPreset(t *testing.T) {
+	b := Binary{
+		Channel: "live",
+		Presets: map[string]Preset{
+			"canary": {
+				Channel: "zcanary",
+				FFlags:  roblox.FFlags{"FFlagMeow": true},
+				Env:     Environment{"MEOW": "purr"},
+			},
+		},
+	}
+
+	if err := b.ApplyPreset("canary"); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Channel != "zcanary" || b.FFlags["FFlagMeow"] != true || b.Env["MEOW"] != "purr" {
+		t.Fatal("expected preset to be applied")
+	}
+
+	if err := b.ApplyPreset("meow"); !errors.Is(err, ErrNoPreset) {
+		t.Fatal("expected missing preset check")
+	}
+
+	b.Presets["bad name"] = Preset{}
+	if err := b.validate(); !errors.Is(err, ErrBadPresetName) {
+		t.Fatal("expected preset name check")
+	}
+}
@@ -15,7 +15,9 @@ var (
 	Downloads = filepath.Join(Cache, "downloads")
 	Logs      = filepath.Join(Cache, "logs")
 	Prefixes  = filepath.Join(Data, "prefixes")
+	Backups   = filepath.Join(Data, "backups")
 	Versions  = filepath.Join(Data, "versions")
+	Runtime   = filepath.Join(xdg.RuntimeDir, "vinegar")
 
 	// Deprecated: Vinegar supports multiple wine prefixes
 	Prefix = filepath.Join(Data, "prefix")
@@ -0,0 +1,164 @@
+// Package events implements a machine-readable stream of Vinegar's
+// progress and state, served as JSON lines over a Unix domain socket.
+//
+// Every line written to a connected client is a single JSON object
+// representing an [Event]:
+//
+//	{"type":"message","time":"2006-01-02T15:04:05Z","binary":"Player","message":"Downloading Player","progress":0}
+//	{"type":"progress","time":"2006-01-02T15:04:05Z","binary":"Player","progress":0.5}
+//	{"type":"launch","time":"2006-01-02T15:04:05Z","binary":"Player","progress":0,"pid":1234}
+//	{"type":"exit","time":"2006-01-02T15:04:05Z","binary":"Player","progress":0,"code":0}
+//	{"type":"activity","time":"2006-01-02T15:04:05Z","binary":"Player","progress":0,"activity":{"playing":true,...}}
+//
+// Fields that are irrelevant to the event's type are omitted, except for
+// progress, which is always present so that the start of a stage can be
+// represented.
+//
+// Clients must read events promptly; a client that cannot be written
+// to within [WriteTimeout] is disconnected.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// WriteTimeout is the maximum amount of time spent writing an event
+// to a single client.
+const WriteTimeout = 100 * time.Millisecond
+
+// Type is the kind of an Event.
+type Type string
+
+const (
+	Message  Type = "message"  // A new stage has begun, such as 'Downloading Player'
+	Desc     Type = "desc"     // Information about the current stage, such as the version
+	Progress Type = "progress" // Progress of the current stage, from 0 to 1
+	Launch   Type = "launch"   // The Roblox process has started
+	Exit     Type = "exit"     // The Roblox process has exited
+	Error    Type = "error"    // Vinegar has failed
+	Activity Type = "activity" // The game activity has changed, if exported
+)
+
+// Event is a representation of a single progress or state change.
+type Event struct {
+	Type     Type      `json:"type"`
+	Time     time.Time `json:"time"`
+	Binary   string    `json:"binary,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Progress float32   `json:"progress"`
+	PID      int       `json:"pid,omitempty"`
+	Code     *int      `json:"code,omitempty"`
+	Activity any       `json:"activity,omitempty"`
+}
+
+// Server broadcasts events to all clients connected to it's socket.
+//
+// A nil Server is valid and will discard all events.
+type Server struct {
+	// Binary is used as the Binary field of sent events.
+	Binary string
+
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// Listen creates a Unix domain socket at the named path and accepts
+// clients in the background. If a stale socket exists at the path,
+// it will be removed.
+func Listen(name string) (*Server, error) {
+	if fi, err := os.Stat(name); err == nil && fi.Mode().Type() == os.ModeSocket {
+		if err := os.Remove(name); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", name)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Listening for event clients", "path", name)
+
+	s := &Server{ln: ln}
+	go s.accept()
+
+	return s, nil
+}
+
+func (s *Server) accept() {
+	for {
+		c, err := s.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to accept event client", "error", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, c)
+		s.mu.Unlock()
+	}
+}
+
+// Send writes the event to all connected clients, removing
+// clients that can no longer be written to. If the event's
+// time is unset, it will be set to the current time.
+func (s *Server) Send(e Event) {
+	if s == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Binary == "" {
+		e.Binary = s.Binary
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("Failed to marshal event", "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conns := s.conns[:0]
+	for _, c := range s.conns {
+		c.SetWriteDeadline(time.Now().Add(WriteTimeout))
+		if _, err := c.Write(line); err != nil {
+			slog.Warn("Disconnecting event client", "error", err)
+			c.Close()
+			continue
+		}
+		conns = append(conns, c)
+	}
+	s.conns = conns
+}
+
+// Close closes all clients and removes the Server's socket.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+	s.mu.Unlock()
+
+	return s.ln.Close()
+}
@@ -0,0 +1,148 @@
+// Package journal implements sending log entries to the systemd journal
+// through it's native protocol.
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+)
+
+// SocketPath is the path of the systemd journal's native protocol socket.
+const SocketPath = "/run/systemd/journal/socket"
+
+// Priority is a syslog priority level.
+type Priority int
+
+const (
+	Err     Priority = 3
+	Warning Priority = 4
+	Info    Priority = 6
+	Debug   Priority = 7
+)
+
+// LevelPriority maps the given slog level to it's syslog priority.
+func LevelPriority(l slog.Level) Priority {
+	switch {
+	case l >= slog.LevelError:
+		return Err
+	case l >= slog.LevelWarn:
+		return Warning
+	case l >= slog.LevelInfo:
+		return Info
+	default:
+		return Debug
+	}
+}
+
+// Conn is a connection to the systemd journal.
+type Conn struct {
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// Dial connects to the systemd journal's socket.
+func Dial() (*Conn, error) {
+	c, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{conn: c}, nil
+}
+
+// Send sends a log entry with the given priority and message, with
+// it's SYSLOG_IDENTIFIER field set to the named identifier.
+func (c *Conn) Send(p Priority, identifier, msg string) error {
+	var b bytes.Buffer
+
+	writeField(&b, "PRIORITY", fmt.Sprint(int(p)))
+	writeField(&b, "SYSLOG_IDENTIFIER", identifier)
+	writeField(&b, "MESSAGE", msg)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.conn.Write(b.Bytes())
+	return err
+}
+
+// Close closes the connection to the journal.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// writeField writes the field in the native protocol's format, which
+// requires values containing newlines to be length-prefixed.
+func writeField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// Handler is a slog.Handler that sends records to the journal,
+// with their attributes appended to the message in key=value form.
+type Handler struct {
+	conn       *Conn
+	identifier string
+	attrs      string
+	group      string
+}
+
+// NewHandler returns a new Handler sending records to c with
+// the named identifier.
+func NewHandler(c *Conn, identifier string) *Handler {
+	return &Handler{conn: c, identifier: identifier}
+}
+
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= slog.LevelInfo
+}
+
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		b.WriteString(h.format(a))
+		return true
+	})
+
+	return h.conn.Send(LevelPriority(r.Level), h.identifier, b.String())
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	for _, a := range attrs {
+		h2.attrs += h.format(a)
+	}
+
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.group += name + "."
+
+	return &h2
+}
+
+func (h *Handler) format(a slog.Attr) string {
+	return fmt.Sprintf(" %s%s=%q", h.group, a.Key, a.Value.String())
+}
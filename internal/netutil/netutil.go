@@ -2,14 +2,116 @@
 package netutil
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"sync"
 )
 
+var httpClient = &http.Client{}
+
+var (
+	pauseMu sync.Mutex
+	resumed = closedChan()
+)
+
+func closedChan() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+// Pause pauses the in-progress and future downloads of Download, DownloadHash
+// and DownloadProgress until Resume is called, by no longer reading from
+// their connections. Servers may close connections paused for too long,
+// which results in the download being retried.
+func Pause() {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	select {
+	case <-resumed:
+		resumed = make(chan struct{})
+	default:
+	}
+}
+
+// Resume resumes downloads paused by Pause.
+func Resume() {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	select {
+	case <-resumed:
+	default:
+		close(resumed)
+	}
+}
+
+// Paused determines if downloads are currently paused.
+func Paused() bool {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	select {
+	case <-resumed:
+		return false
+	default:
+		return true
+	}
+}
+
+// pausableReader is an io.Reader that blocks while downloads are paused.
+type pausableReader struct {
+	r io.Reader
+}
+
+func (pr pausableReader) Read(p []byte) (int, error) {
+	pauseMu.Lock()
+	c := resumed
+	pauseMu.Unlock()
+
+	<-c
+	return pr.r.Read(p)
+}
+
+// SetClient sets the http.Client used to make requests.
+func SetClient(client *http.Client) {
+	httpClient = client
+}
+
+// Head issues a HEAD request to the named url.
+func Head(url string) (*http.Response, error) {
+	return httpClient.Head(url)
+}
+
+// CertPool returns a copy of the system's certificate pool with the
+// PEM encoded certificates in the named files appended to it.
+func CertPool(files ...string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		pem, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", f)
+		}
+	}
+
+	return pool, nil
+}
+
 // DrawFunc is the callback type for drawing progress, it will
 // be ran in a goroutine.
 type DrawFunc func(float32)
@@ -40,7 +142,7 @@ func DownloadProgress(url, file string, df DrawFunc) error {
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return err
 	}
@@ -55,7 +157,7 @@ func DownloadProgress(url, file string, df DrawFunc) error {
 		draw:  df,
 	}
 
-	_, err = io.Copy(out, io.TeeReader(resp.Body, pc))
+	_, err = io.Copy(out, io.TeeReader(pausableReader{resp.Body}, pc))
 	if err != nil {
 		return err
 	}
@@ -67,9 +169,20 @@ func DownloadProgress(url, file string, df DrawFunc) error {
 // occurs when downloading the file. Download will retry 3 times before
 // returning a final error.
 func Download(url, file string) error {
+	return DownloadHash(url, file, nil)
+}
+
+// DownloadHash is like Download, but additionally writes the downloaded
+// bytes to h as they are received, to allow verifying the file without
+// reading it again. h is reset before each attempt, and may be nil.
+func DownloadHash(url, file string, h hash.Hash) error {
 	retries := 3
 	for i := 0; i < retries; i++ {
-		err := download(url, file)
+		if h != nil {
+			h.Reset()
+		}
+
+		err := download(url, file, h)
 		if err == nil {
 			break
 		}
@@ -87,14 +200,14 @@ func Download(url, file string) error {
 	return nil
 }
 
-func download(url, file string) error {
+func download(url, file string, h hash.Hash) error {
 	out, err := os.Create(file)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return err
 	}
@@ -104,7 +217,12 @@ func download(url, file string) error {
 		return fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
 	}
 
-	_, err = io.Copy(out, resp.Body)
+	var w io.Writer = out
+	if h != nil {
+		w = io.MultiWriter(out, h)
+	}
+
+	_, err = io.Copy(w, pausableReader{resp.Body})
 	if err != nil {
 		return err
 	}
@@ -112,9 +230,90 @@ func download(url, file string) error {
 	return nil
 }
 
+// DownloadResume is like DownloadHash, but continues downloading the named
+// file from it's existing size with a Range request, rather than restarting,
+// both when retrying and for files left behind by a previous interrupted
+// download. If the server does not support Range requests, the file is
+// downloaded again from the start. The file's existing contents are also
+// written to h, and the file is never removed on failure.
+func DownloadResume(url, file string, h hash.Hash) error {
+	var err error
+
+	retries := 3
+	for i := 0; i < retries; i++ {
+		err = resume(url, file, h)
+		if err == nil {
+			return nil
+		}
+
+		if _, ok := err.(*os.PathError); ok || errors.Is(err, ErrBadStatus) {
+			return err
+		}
+
+		log.Printf("Download %s failed, resuming...", url)
+	}
+
+	return err
+}
+
+func resume(url, file string, h hash.Hash) error {
+	out, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	h.Reset()
+	// Hashing the existing contents also seeks to their end.
+	off, err := io.Copy(h, out)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if off > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", off))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The file is already complete, or larger than the
+		// requested file, which the caller's checksum will catch.
+		return nil
+	case http.StatusOK:
+		if off == 0 {
+			break
+		}
+
+		log.Printf("Download %s cannot be resumed, restarting...", url)
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		h.Reset()
+	default:
+		return fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
+	}
+
+	_, err = io.Copy(io.MultiWriter(out, h), pausableReader{resp.Body})
+	return err
+}
+
 // Body retrieves the body of the named url to string form.
 func Body(url string) (string, error) {
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get(url)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,68 @@
+package netutil
+
+import (
+	"bytes"
+	"crypto/md5"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadResume(t *testing.T) {
+	content := strings.Repeat("meow", 1024)
+	ranged := false
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged = true
+		}
+		http.ServeContent(w, r, "meow", time.Time{}, strings.NewReader(content))
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "meow.part")
+	if err := os.WriteFile(file, []byte(content[:1000]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := md5.New()
+	if err := DownloadResume(srv.URL, file, h); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(file)
+	if !ranged || string(got) != content {
+		t.Fatalf("expected resumed download (ranged %t)", ranged)
+	}
+
+	want := md5.Sum([]byte(content))
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Error("expected hash of the whole file")
+	}
+}
+
+func TestDownloadResumeRestart(t *testing.T) {
+	content := strings.Repeat("mrrp", 1024)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content)) // Range unsupported
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "mrrp.part")
+	if err := os.WriteFile(file, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DownloadResume(srv.URL, file, md5.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(file); string(got) != content {
+		t.Fatal("expected download to restart")
+	}
+}
@@ -3,24 +3,59 @@ package state
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/vinegarhq/vinegar/internal/dirs"
+	"github.com/vinegarhq/vinegar/roblox"
 	"github.com/vinegarhq/vinegar/roblox/bootstrapper"
 )
 
-var path = filepath.Join(dirs.Data, "state.json")
+var (
+	path     = filepath.Join(dirs.Data, "state.json")
+	versions = dirs.Versions
+)
+
+// Format is the version of the state file's layout. It should be
+// incremented whenever the layout changes in a way that requires
+// existing state files to be migrated in [State.migrate].
+const Format = 1
+
+var (
+	ErrNewerFormat = errors.New("state file was written by a newer version of vinegar")
+	ErrNoPrevious  = errors.New("no previous version to roll back to")
+)
+
+// Deployment is a record of a Binary's installed deployment.
+type Deployment struct {
+	Channel   string
+	GUID      string
+	Packages  []string // Package names
+	Installed time.Time
+}
 
 // BinaryState is used track a Binary's deployment and wineprefix.
 type Binary struct {
-	DxvkVersion string
+	DxvkVersion string // Installed DXVK version or the path it was installed from
+	Vkd3d       string // Path VKD3D-Proton was installed from, empty if not installed
+	WebView     string // Installed WebView version, empty if not installed
+	WineVersion string // Wine version the Wineprefix was last used with
 	Version     string
 	Packages    []string
+	Deployment  *Deployment  `json:",omitempty"`
+	Launches    int          // Launches since the installation was last verified
+	FFlags      []string     `json:",omitempty"` // Names of the FFlags last applied to the settings file
+	Previous    []Deployment `json:",omitempty"` // Previously installed deployments kept to roll back to, newest first
+	Skipped     string       `json:",omitempty"` // Version rolled back from, which is not updated to again
 }
 
 // State holds various details about Vinegar's current state.
 type State struct {
+	Format int
 	Player Binary
 	Studio Binary
 }
@@ -28,25 +63,84 @@ type State struct {
 // Load returns the state file's contents in State form.
 //
 // If the state file does not exist or is empty, an
-// empty state is returned.
+// empty state is returned. If the state file is corrupted,
+// it is backed up and a state re-derived from the installed
+// versions is returned instead.
 func Load() (State, error) {
 	var state State
 
 	f, err := os.ReadFile(path)
 	if (err != nil && errors.Is(err, os.ErrNotExist)) || len(f) == 0 {
-		return State{}, nil
+		return State{Format: Format}, nil
 	}
 	if err != nil {
 		return State{}, err
 	}
 
 	if err := json.Unmarshal(f, &state); err != nil {
+		return reset(err)
+	}
+
+	if err := state.migrate(); err != nil {
 		return State{}, err
 	}
 
 	return state, nil
 }
 
+// reset backs up the corrupted state file and returns a
+// state with the installed Binary versions re-derived.
+func reset(cause error) (State, error) {
+	backup := path + ".corrupt-" + strconv.FormatInt(time.Now().Unix(), 10)
+	if err := os.Rename(path, backup); err != nil {
+		return State{}, fmt.Errorf("backup corrupted state: %w", err)
+	}
+
+	slog.Warn("State file is corrupted, starting with a fresh state",
+		"error", cause, "backup", backup)
+
+	s := State{Format: Format}
+	s.Player.Version = installed(roblox.Player)
+	s.Studio.Version = installed(roblox.Studio)
+
+	return s, nil
+}
+
+// installed returns the most recently modified version directory
+// containing the given Binary's executable, or an empty string
+// if none could be found.
+func installed(bt roblox.BinaryType) (ver string) {
+	entries, _ := os.ReadDir(versions)
+
+	var latest time.Time
+	for _, e := range entries {
+		fi, err := os.Stat(filepath.Join(versions, e.Name(), bt.Executable()))
+		if err != nil || !e.IsDir() {
+			continue
+		}
+
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+			ver = e.Name()
+		}
+	}
+
+	return
+}
+
+// migrate upgrades the state from an older format to the current Format.
+func (s *State) migrate() error {
+	if s.Format > Format {
+		return fmt.Errorf("%w: %d", ErrNewerFormat, s.Format)
+	}
+
+	// Format 0 predates the format field and deployment records, which
+	// will be filled in on the next installation.
+	s.Format = Format
+
+	return nil
+}
+
 // Save saves the current state to the state file.
 func (s *State) Save() error {
 	if err := dirs.Mkdirs(filepath.Dir(path)); err != nil {
@@ -74,9 +168,50 @@ func (s *State) Save() error {
 // Add formats the given package manifest into a Binary form.
 func (bs *Binary) Add(pm *bootstrapper.PackageManifest) {
 	bs.Version = pm.Deployment.GUID
+	bs.Packages = nil
+	bs.Launches = 0
+	bs.Deployment = &Deployment{
+		Channel:   pm.Deployment.Channel,
+		GUID:      pm.Deployment.GUID,
+		Installed: time.Now().UTC(),
+	}
+
 	for _, pkg := range pm.Packages {
 		bs.Packages = append(bs.Packages, pkg.Checksum)
+		bs.Deployment.Packages = append(bs.Deployment.Packages, pkg.Name)
+	}
+}
+
+// Installed returns the names of the Binary's installed packages mapped
+// to their checksums, or nil if they are not known, such as with states
+// written before the package names were recorded.
+func (bs *Binary) Installed() map[string]string {
+	if bs.Deployment == nil || len(bs.Deployment.Packages) != len(bs.Packages) {
+		return nil
+	}
+
+	pkgs := make(map[string]string, len(bs.Packages))
+	for i, name := range bs.Deployment.Packages {
+		pkgs[name] = bs.Packages[i]
+	}
+
+	return pkgs
+}
+
+// String returns a short description of the deployment, such as
+// 'version-0123456789abcdef (live) installed 2006-01-02T15:04:05Z, 12 packages'.
+func (d *Deployment) String() string {
+	if d == nil {
+		return "not installed"
+	}
+
+	channel := d.Channel
+	if channel == "" {
+		channel = "live"
 	}
+
+	return fmt.Sprintf("%s (%s) installed %s, %d packages",
+		d.GUID, channel, d.Installed.Format(time.RFC3339), len(d.Packages))
 }
 
 // Packages returns all the available Binary packages from the state.
@@ -88,11 +223,52 @@ func (s *State) Packages() (pkgs []string) {
 	return
 }
 
-// Packages returns all the available Binary versions from the state.
+// Versions returns all the available Binary versions from the state,
+// including the previous versions kept to roll back to.
 func (s *State) Versions() (vers []string) {
 	for _, bs := range []Binary{s.Player, s.Studio} {
 		vers = append(vers, bs.Version)
+		for _, d := range bs.Previous {
+			vers = append(vers, d.GUID)
+		}
 	}
 
 	return
 }
+
+// Keep records the Binary's installed deployment as a previous version
+// to roll back to, retaining at most the given amount of previous
+// versions. Nothing is kept if keep is not positive.
+func (bs *Binary) Keep(keep int) {
+	if keep <= 0 || bs.Deployment == nil {
+		bs.Previous = nil
+		return
+	}
+
+	bs.Previous = append([]Deployment{*bs.Deployment}, bs.Previous...)
+	if len(bs.Previous) > keep {
+		bs.Previous = bs.Previous[:keep]
+	}
+}
+
+// Rollback switches the Binary's installed deployment to the newest
+// previous version, which will not be updated to again until a newer
+// version than the one rolled back from is available. The deployment
+// rolled back from is kept as a previous version in it's place.
+func (bs *Binary) Rollback() (*Deployment, error) {
+	if len(bs.Previous) == 0 || bs.Deployment == nil {
+		return nil, ErrNoPrevious
+	}
+
+	prev := bs.Previous[0]
+	bs.Previous[0] = *bs.Deployment
+	bs.Skipped = bs.Deployment.GUID
+
+	bs.Version = prev.GUID
+	bs.Deployment = &prev
+	// The checksums of the previous version's packages are not kept.
+	bs.Packages = nil
+	bs.Launches = 0
+
+	return &prev, nil
+}
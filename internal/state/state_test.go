@@ -1,7 +1,9 @@
 package state
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -52,3 +54,63 @@ func TestState(t *testing.T) {
 		t.Fatal("want meow packages")
 	}
 }
+
+func TestStateCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path = filepath.Join(dir, "state.json")
+	versions = filepath.Join(dir, "versions")
+
+	exe := filepath.Join(versions, "version-meow", roblox.Studio.Executable())
+	if err := os.MkdirAll(filepath.Dir(exe), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"Player": {`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Studio.Version != "version-meow" || s.Player.Version != "" {
+		t.Fatalf("want re-derived versions, got %+v", s)
+	}
+
+	backups, _ := filepath.Glob(path + ".corrupt-*")
+	if len(backups) != 1 {
+		t.Fatal("want corrupted state backed up")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	var bs Binary
+
+	if _, err := bs.Rollback(); !errors.Is(err, ErrNoPrevious) {
+		t.Error("expected no previous version")
+	}
+
+	for _, guid := range []string{"version-a", "version-b", "version-c"} {
+		v := bootstrapper.NewDeployment(roblox.Player, "", guid)
+		bs.Keep(1)
+		bs.Add(&bootstrapper.PackageManifest{Deployment: &v})
+	}
+
+	if len(bs.Previous) != 1 || bs.Previous[0].GUID != "version-b" {
+		t.Fatalf("expected only version-b to be kept, got %v", bs.Previous)
+	}
+
+	d, err := bs.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.GUID != "version-b" || bs.Version != "version-b" || bs.Skipped != "version-c" ||
+		bs.Previous[0].GUID != "version-c" {
+		t.Errorf("unexpected rollback state %+v", bs)
+	}
+}
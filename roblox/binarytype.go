@@ -35,6 +35,35 @@ func (bt BinaryType) BinaryName() string {
 	}
 }
 
+// Updater returns the file name of the executable the named
+// BinaryType runs to update itself in-place.
+//
+// Does not support platforms other than Windows.
+func (bt BinaryType) Updater() string {
+	switch bt {
+	case Player:
+		return "RobloxPlayerLauncher.exe"
+	case Studio:
+		return "RobloxStudioLauncherBeta.exe"
+	default:
+		return "unknown"
+	}
+}
+
+// RegistryEnvironment returns the name of the registry key within
+// 'HKEY_CURRENT_USER\Software\ROBLOX Corporation\Environments' the
+// named BinaryType records it's installed version in.
+func (bt BinaryType) RegistryEnvironment() string {
+	switch bt {
+	case Player:
+		return "roblox-player"
+	case Studio:
+		return "roblox-studio"
+	default:
+		return "unknown"
+	}
+}
+
 // Executable returns the executable file name for the
 // named BinaryType
 //
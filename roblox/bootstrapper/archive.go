@@ -2,6 +2,7 @@ package bootstrapper
 
 import (
 	"archive/zip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -48,7 +49,57 @@ func extract(src string, dir string) error {
 	return nil
 }
 
+// link hard links the files listed in the named src archive from the
+// named old directory to the named dir directory. The files must be
+// of the same size as those in the archive.
+func link(src, old, dir string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		name := strings.ReplaceAll(f.Name, `\`, "/")
+		dest := filepath.Join(dir, name)
+
+		if f.FileInfo().IsDir() || dir == dest {
+			continue
+		}
+
+		if !strings.HasPrefix(dest, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path: %s", dest)
+		}
+
+		oldf := filepath.Join(old, name)
+		fi, err := os.Stat(oldf)
+		if err != nil {
+			return err
+		}
+
+		if fi.Size() != int64(f.UncompressedSize64) {
+			return fmt.Errorf("%s has been modified", oldf)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+
+		if err := os.Link(oldf, dest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func extractFile(src *zip.File, dest string) error {
+	// The destination may be hard linked to a file of another
+	// installation by link, which must be left untouched.
+	if err := os.Remove(dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
 	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, src.Mode())
 	if err != nil {
 		return err
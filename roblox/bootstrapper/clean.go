@@ -0,0 +1,79 @@
+package bootstrapper
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// StaleAge is how long a lock or temporary file must not have been
+	// modified for, to be considered left behind by an interrupted
+	// installation.
+	StaleAge = 30 * time.Minute
+
+	// StalePartAge is how long a partial download must not have been
+	// modified for to be removed, rather than resumed.
+	StalePartAge = 7 * 24 * time.Hour
+)
+
+// staleExts is a list of the file extensions of
+// lock and temporary files.
+var staleExts = []string{".lock", ".tmp"}
+
+// CleanStale removes the files within the named download directory that
+// would block or break new downloads: lock and temporary files not modified
+// within [StaleAge], partial downloads not modified within [StalePartAge],
+// and any file that cannot be written to, such as those owned by another
+// user after running as root. The paths of the removed files are returned.
+func CleanStale(dir string) (removed []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		reason := ""
+
+		age := time.Since(fi.ModTime())
+		ext := filepath.Ext(path)
+
+		if slices.Contains(staleExts, ext) && age > StaleAge ||
+			ext == PartExt && age > StalePartAge {
+			reason = "stale"
+		} else if f, err := os.OpenFile(path, os.O_WRONLY, 0); errors.Is(err, fs.ErrPermission) {
+			reason = "not writable"
+		} else if err == nil {
+			f.Close()
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		slog.Info("Removing leftover download file", "path", path, "reason", reason, "modified", fi.ModTime())
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
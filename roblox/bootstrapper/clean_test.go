@@ -0,0 +1,39 @@
+package bootstrapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanStale(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "meow.lock")
+	fresh := filepath.Join(dir, "mrrp.part")
+	pkg := filepath.Join(dir, "026b271a21b03f2e564c036525356db5")
+
+	for _, p := range []string{stale, fresh, pkg} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := time.Now().Add(-2 * StaleAge)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := CleanStale(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(removed) != 1 || removed[0] != stale {
+		t.Fatalf("expected only %s to be removed, got %v", stale, removed)
+	}
+
+	if _, err := CleanStale(filepath.Join(dir, "nonexistent")); err != nil {
+		t.Fatal("expected missing directory to be ignored")
+	}
+}
@@ -3,7 +3,8 @@ package bootstrapper
 import (
 	"errors"
 	"log/slog"
-	"net/http"
+
+	"github.com/vinegarhq/vinegar/internal/netutil"
 )
 
 var (
@@ -26,7 +27,7 @@ func Mirror() (string, error) {
 	slog.Info("Finding an accessible deploy mirror")
 
 	for _, m := range Mirrors {
-		resp, err := http.Head(m + "/" + "version")
+		resp, err := netutil.Head(m + "/" + "version")
 		if err != nil {
 			slog.Error("Bad deploy mirror", "mirror", m, "error", err)
 
@@ -3,7 +3,9 @@ package bootstrapper
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"os"
@@ -21,6 +23,17 @@ type Package struct {
 
 type Packages []Package
 
+// ErrChecksumMismatch is returned when a package's contents do not
+// match the checksum listed in the package manifest.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// PartExt is the file extension of partially downloaded packages.
+const PartExt = ".part"
+
+// downloadAttempts is the amount of times a package is downloaded
+// when the downloaded package does not match it's checksum.
+const downloadAttempts = 2
+
 // Verify checks the named package source file against it's checksum
 func (p *Package) Verify(src string) error {
 	slog.Info("Verifying Package", "name", p.Name, "path", src)
@@ -35,10 +48,13 @@ func (p *Package) Verify(src string) error {
 	if _, err := io.Copy(h, f); err != nil {
 		return err
 	}
-	fsum := hex.EncodeToString(h.Sum(nil))
 
-	if p.Checksum != fsum {
-		return fmt.Errorf("package %s is corrupted, please re-download or delete package", p.Name)
+	return p.verifyHash(h)
+}
+
+func (p *Package) verifyHash(h hash.Hash) error {
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != p.Checksum {
+		return fmt.Errorf("package %s: %w got %s want %s", p.Name, ErrChecksumMismatch, sum, p.Checksum)
 	}
 
 	return nil
@@ -47,20 +63,95 @@ func (p *Package) Verify(src string) error {
 // Download will download the package to the named dest destination
 // directory with the given deployURL deploy mirror; if the package
 // exists and has the correct checksum, it will return immediately.
+//
+// The package is downloaded to a partial file alongside dest, which
+// is resumed if it was left behind by an interrupted download, and is
+// hashed while it is being downloaded. Only if it's checksum matches,
+// it is renamed to dest, otherwise it is removed and the package is
+// downloaded again, once.
 func (p *Package) Download(dest, deployURL string) error {
 	if err := p.Verify(dest); err == nil {
 		slog.Info("Package is already downloaded", "name", p.Name, "file", dest)
 		return nil
 	}
 
-	url := deployURL + "-" + p.Name
-	slog.Info("Downloading package", "url", url, "path", dest)
+	return p.download(dest, []string{deployURL}, nil)
+}
+
+// progressHash is a hash.Hash that calls fn with the amount
+// of bytes written to it, if fn is set.
+type progressHash struct {
+	hash.Hash
+	fn func(int)
+}
+
+func (h progressHash) Write(p []byte) (int, error) {
+	if h.fn != nil {
+		h.fn(len(p))
+	}
+
+	return h.Hash.Write(p)
+}
+
+// download downloads the package from the first of the given deploy URLs
+// that serves it, calling progress with the amount of bytes received as
+// they are received.
+func (p *Package) download(dest string, deployURLs []string, progress func(int)) (err error) {
+	for i := 0; i < downloadAttempts; i++ {
+		part := dest + PartExt
+		h := progressHash{md5.New(), progress}
+		if err := p.fetch(part, deployURLs, h); err != nil {
+			return fmt.Errorf("download package %s: %w", p.Name, err)
+		}
+
+		err = p.verifyHash(h)
+		if err == nil {
+			return os.Rename(part, dest)
+		}
+
+		os.Remove(part)
+		slog.Warn("Downloaded package is corrupted", "name", p.Name, "error", err)
+	}
+
+	return err
+}
+
+// fetch downloads the package to the named file, trying each of the
+// given deploy URLs in order until one of them serves it. Since the
+// partial file is kept, the next mirror resumes where the last failed.
+func (p *Package) fetch(file string, deployURLs []string, h hash.Hash) (err error) {
+	for _, durl := range deployURLs {
+		url := durl + "-" + p.Name
+		slog.Info("Downloading package", "url", url, "path", file)
+
+		err = netutil.DownloadResume(url, file, h)
+		if err == nil {
+			slog.Info("Downloaded package", "name", p.Name, "url", url)
+			return nil
+		}
 
-	if err := netutil.Download(url, dest); err != nil {
-		return fmt.Errorf("download package %s: %w", p.Name, err)
+		// Other mirrors cannot fix errors writing the file.
+		if _, ok := err.(*os.PathError); ok {
+			return err
+		}
+
+		slog.Warn("Deploy mirror failed to serve package", "url", url, "error", err)
+	}
+
+	return err
+}
+
+// Link hard links the package's files, as listed by the named package
+// source file, from the named old directory of an existing installation
+// to the given destination directory, to reuse them instead of extracting
+// the package.
+func (p *Package) Link(src, old, dest string) error {
+	if err := link(src, old, dest); err != nil {
+		return fmt.Errorf("link package %s (%s): %w", p.Name, src, err)
 	}
 
-	return p.Verify(dest)
+	slog.Info("Reused package", "name", p.Name, "old", old, "dest", dest)
+	return nil
 }
 
 // Extract extracts the named package source file to a given destination directory
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/vinegarhq/vinegar/internal/netutil"
 )
@@ -16,6 +19,18 @@ type PackageManifest struct {
 	*Deployment
 	DeployURL string
 	Packages
+
+	// Fallbacks are the deploy URLs of the other mirrors, which
+	// are tried in order if a package fails to download from DeployURL.
+	Fallbacks []string
+
+	// ExtractThreads is the maximum amount of packages
+	// extracted concurrently, unlimited if not positive.
+	ExtractThreads int
+
+	// DownloadThreads is the maximum amount of packages
+	// downloaded concurrently, unlimited if not positive.
+	DownloadThreads int
 }
 
 var (
@@ -35,19 +50,34 @@ func channelPath(channel string) string {
 	return "/channel/" + channel + "/"
 }
 
-// FetchPackageManifest retrieves a package manifest for the given binary deployment.
+// FetchPackageManifest retrieves a package manifest for the given binary
+// deployment from the first of [Mirrors] that serves it.
 func FetchPackageManifest(d *Deployment) (PackageManifest, error) {
-	m, err := Mirror()
-	if err != nil {
-		return PackageManifest{}, err
+	if len(Mirrors) == 0 {
+		return PackageManifest{}, ErrNoMirrorFound
 	}
 
-	durl := m + channelPath(d.Channel) + d.GUID
-	url := durl + "-rbxPkgManifest.txt"
+	durls := make([]string, len(Mirrors))
+	for i, m := range Mirrors {
+		durls[i] = m + channelPath(d.Channel) + d.GUID
+	}
 
-	slog.Info("Fetching Package Manifest", "url", url)
+	var smanif string
+	var err error
+	for i, durl := range durls {
+		url := durl + "-rbxPkgManifest.txt"
+		slog.Info("Fetching Package Manifest", "url", url)
+
+		smanif, err = netutil.Body(url)
+		if err == nil {
+			slog.Info("Fetched Package Manifest", "mirror", Mirrors[i])
+			// The serving mirror is preferred for the packages.
+			durls[0], durls[i] = durls[i], durls[0]
+			break
+		}
 
-	smanif, err := netutil.Body(url)
+		slog.Warn("Deploy mirror failed to serve package manifest", "mirror", Mirrors[i], "error", err)
+	}
 	if err != nil {
 		return PackageManifest{}, fmt.Errorf("fetch %s package manifest: %w", d.GUID, err)
 	}
@@ -65,11 +95,17 @@ func FetchPackageManifest(d *Deployment) (PackageManifest, error) {
 
 	return PackageManifest{
 		Deployment: d,
-		DeployURL:  durl,
+		DeployURL:  durls[0],
 		Packages:   pkgs,
+		Fallbacks:  durls[1:],
 	}, nil
 }
 
+// deployURLs returns the deploy URLs packages are downloaded from, in order.
+func (pm *PackageManifest) deployURLs() []string {
+	return append([]string{pm.DeployURL}, pm.Fallbacks...)
+}
+
 func parsePackages(manifest []string) (Packages, error) {
 	pkgs := make(Packages, 0)
 
@@ -106,3 +142,112 @@ func parsePackages(manifest []string) (Packages, error) {
 
 	return pkgs, nil
 }
+
+// Download downloads all of the manifest's packages to the named
+// directory, named after their checksums, reporting progress to r.
+//
+// Packages already present within the directory are verified first,
+// and only those missing or corrupted are downloaded, which are returned.
+// The download progress is reported in bytes downloaded across all packages.
+func (pm *PackageManifest) Download(dir string, r Reporter) (Packages, error) {
+	var mu sync.Mutex
+	var missing Packages
+
+	err := pm.Packages.perform(StageVerify, r, 0, func(pkg Package) error {
+		if err := pkg.Verify(filepath.Join(dir, pkg.Checksum)); err != nil {
+			mu.Lock()
+			missing = append(missing, pkg)
+			mu.Unlock()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Downloading Packages", "guid", pm.Deployment.GUID,
+		"count", len(missing), "cached", len(pm.Packages)-len(missing),
+		"threads", pm.DownloadThreads)
+
+	var total int64
+	for _, pkg := range missing {
+		total += pkg.ZipSize
+	}
+
+	// Progress is only reported once per percent, as it
+	// would otherwise be reported for every read.
+	var done, percent atomic.Int64
+	report := func(n int, message string) {
+		cur := min(done.Add(int64(n)), total)
+		if p := cur * 100 / max(total, 1); percent.Swap(p) == p && message == "" && n > 0 {
+			return
+		}
+
+		r.Report(StageDownload, int(cur), int(total), message)
+	}
+	report(0, "")
+
+	return missing, missing.perform(StageDownload, NopReporter{}, pm.DownloadThreads, func(pkg Package) error {
+		err := pkg.download(filepath.Join(dir, pkg.Checksum), pm.deployURLs(), func(n int) {
+			report(n, "")
+		})
+		if err == nil {
+			report(0, pkg.Name)
+		}
+
+		return err
+	})
+}
+
+// Extract extracts all of the manifest's packages downloaded by Download
+// in the named src directory to their package directories within the named
+// dest directory, reporting progress to r.
+func (pm *PackageManifest) Extract(src, dest string, r Reporter) error {
+	slog.Info("Extracting Packages", "guid", pm.Deployment.GUID, "count", len(pm.Packages))
+
+	pkgDirs := BinaryDirectories(pm.Deployment.Type)
+
+	return pm.Packages.perform(StageExtract, r, pm.ExtractThreads, func(pkg Package) error {
+		dir, ok := pkgDirs[pkg.Name]
+		if !ok {
+			return fmt.Errorf("unhandled package: %s", pkg.Name)
+		}
+
+		return pkg.Extract(filepath.Join(src, pkg.Checksum), filepath.Join(dest, dir))
+	})
+}
+
+// Reuse hard links the files of the manifest's packages that are unchanged
+// from the installed packages, given as package names mapped to their
+// checksums, from the named old installation directory to the named dest
+// directory. The packages downloaded within the named src directory are
+// used to list their files.
+//
+// A copy of the manifest with only the packages that could not be reused
+// is returned, which must still be downloaded and extracted.
+func (pm *PackageManifest) Reuse(installed map[string]string, src, old, dest string) PackageManifest {
+	delta := *pm
+	delta.Packages = nil
+
+	pkgDirs := BinaryDirectories(pm.Deployment.Type)
+
+	for _, pkg := range pm.Packages {
+		dir, ok := pkgDirs[pkg.Name]
+		if !ok || installed[pkg.Name] != pkg.Checksum {
+			delta.Packages = append(delta.Packages, pkg)
+			continue
+		}
+
+		err := pkg.Link(filepath.Join(src, pkg.Checksum), filepath.Join(old, dir), filepath.Join(dest, dir))
+		if err != nil {
+			slog.Warn("Could not reuse package, installing it instead", "name", pkg.Name, "error", err)
+			delta.Packages = append(delta.Packages, pkg)
+		}
+	}
+
+	slog.Info("Reused unchanged packages", "guid", pm.Deployment.GUID,
+		"reused", len(pm.Packages)-len(delta.Packages), "changed", len(delta.Packages))
+
+	return delta
+}
@@ -1,9 +1,18 @@
 package bootstrapper
 
 import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
+
+	"github.com/vinegarhq/vinegar/roblox"
 )
 
 func TestParsePackages(t *testing.T) {
@@ -119,3 +128,89 @@ func TestChannelPath(t *testing.T) {
 		t.Fatal("expected channel path")
 	}
 }
+
+func writeZip(t *testing.T, name string, files map[string]string) {
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for n, c := range files {
+		w, err := zw.Create(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(c))
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReuse(t *testing.T) {
+	src, old, dest := t.TempDir(), t.TempDir(), t.TempDir()
+
+	writeZip(t, filepath.Join(src, "same"), map[string]string{`shaders\a.bin`: "meow"})
+	os.MkdirAll(filepath.Join(old, "shaders", "shaders"), 0o755)
+	os.WriteFile(filepath.Join(old, "shaders", "shaders", "a.bin"), []byte("meow"), 0o644)
+
+	pm := PackageManifest{
+		Deployment: &Deployment{Type: roblox.Player},
+		Packages: Packages{
+			{Name: "shaders.zip", Checksum: "same"},
+			{Name: "ssl.zip", Checksum: "new"},
+		},
+	}
+
+	delta := pm.Reuse(map[string]string{
+		"shaders.zip": "same",
+		"ssl.zip":     "old",
+	}, src, old, dest)
+
+	if len(delta.Packages) != 1 || delta.Packages[0].Name != "ssl.zip" {
+		t.Fatalf("unexpected changed packages %v", delta.Packages)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dest, "shaders", "shaders", "a.bin")); err != nil || string(b) != "meow" {
+		t.Fatalf("expected reused file: %v", err)
+	}
+
+	// Modified files must not be reused.
+	os.WriteFile(filepath.Join(old, "shaders", "shaders", "a.bin"), []byte("meowmeow"), 0o644)
+	delta = pm.Reuse(map[string]string{"shaders.zip": "same"}, src, old, t.TempDir())
+	if len(delta.Packages) != 2 {
+		t.Fatalf("expected modified package to not be reused")
+	}
+}
+
+func TestFetchMirrorFallback(t *testing.T) {
+	const body = "meow"
+	sum := md5.Sum([]byte(body))
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer good.Close()
+
+	pm := PackageManifest{
+		DeployURL: bad.URL + "/version-1",
+		Fallbacks: []string{good.URL + "/version-1"},
+	}
+	pkg := Package{Name: "a.zip", Checksum: hex.EncodeToString(sum[:])}
+	dest := filepath.Join(t.TempDir(), pkg.Checksum)
+
+	if err := pkg.download(dest, pm.deployURLs(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := os.ReadFile(dest); err != nil || string(b) != body {
+		t.Fatalf("expected package from fallback mirror: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package bootstrapper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Stage is a stage of installing a Binary's packages.
+type Stage string
+
+const (
+	StageVerify   Stage = "verify"
+	StageDownload Stage = "download"
+	StageExtract  Stage = "extract"
+)
+
+// Reporter receives the progress of the bootstrapper's operations, such
+// as a splash window or a socket. current out of total units of the stage
+// have been completed, and message describes the last completed unit.
+//
+// Report may be called concurrently.
+type Reporter interface {
+	Report(stage Stage, current, total int, message string)
+}
+
+// NopReporter is a Reporter that discards all progress.
+type NopReporter struct{}
+
+func (NopReporter) Report(Stage, int, int, string) {}
+
+// TextReporter is a Reporter that writes progress as lines of text to W.
+type TextReporter struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (r *TextReporter) Report(stage Stage, current, total int, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(r.W, "%s: %d/%d %s\n", stage, current, total, message)
+}
+
+// perform concurrently calls fn for every package, at most limit at a
+// time if positive, reporting each completed package to r as part of
+// the given stage. Once fn fails, the packages that have not yet been
+// started are skipped, and the first error is returned.
+func (pkgs Packages) perform(stage Stage, r Reporter, limit int, fn func(Package) error) error {
+	var mu sync.Mutex
+	done := 0
+	eg, ctx := errgroup.WithContext(context.Background())
+	if limit > 0 {
+		eg.SetLimit(limit)
+	}
+
+	r.Report(stage, 0, len(pkgs), "")
+
+	for _, p := range pkgs {
+		p := p
+		eg.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := fn(p); err != nil {
+				return err
+			}
+
+			mu.Lock()
+			done++
+			r.Report(stage, done, len(pkgs), p.Name)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
@@ -0,0 +1,37 @@
+package bootstrapper
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPerformReport(t *testing.T) {
+	var buf bytes.Buffer
+	r := &TextReporter{W: &buf}
+
+	pkgs := Packages{{Name: "foo.zip"}}
+	if err := pkgs.perform(StageExtract, r, 0, func(Package) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "extract: 0/1 \nextract: 1/1 foo.zip\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+
+	errMeow := errors.New("meow")
+	if err := pkgs.perform(StageExtract, NopReporter{}, 1, func(Package) error { return errMeow }); !errors.Is(err, errMeow) {
+		t.Fatal("expected package error")
+	}
+
+	calls := 0
+	pkgs = Packages{{Name: "foo.zip"}, {Name: "bar.zip"}, {Name: "baz.zip"}}
+	err := pkgs.perform(StageExtract, NopReporter{}, 1, func(Package) error {
+		calls++
+		return errMeow
+	})
+	if !errors.Is(err, errMeow) || calls != 1 {
+		t.Fatalf("expected remaining packages to be skipped after failure, got %d calls: %v", calls, err)
+	}
+}
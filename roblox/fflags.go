@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 )
 
-var ErrInvalidRenderer = errors.New("invalid renderer given")
+var (
+	ErrInvalidRenderer = errors.New("invalid renderer given")
+	ErrBadFFlagValue   = errors.New("fflag value must be a boolean, integer or string")
+)
 
 // defaultRenderer is used as the default renderer when
 // no explicit named renderer argument has been given.
@@ -24,14 +28,83 @@ var renderers = []string{
 // FFlags is Roblox's Fast Flags implemented in map form.
 type FFlags map[string]interface{}
 
-// Apply creates and compiles the FFlags file and
-// directory in the named versionDir.
-func (f FFlags) Apply(versionDir string) error {
+// SettingsFile returns the path to the FFlags settings file read by
+// the named BinaryType's installation in the named versionDir.
+//
+// The Player reads ClientAppSettings.json, while Studio prefers
+// StudioAppSettings.json if its installation ships one, and otherwise
+// falls back to the Player's file.
+func SettingsFile(bt BinaryType, versionDir string) string {
 	dir := filepath.Join(versionDir, "ClientSettings")
-	path := filepath.Join(dir, "ClientAppSettings.json")
 
-	err := os.Mkdir(dir, 0o755)
-	if err != nil && !errors.Is(err, os.ErrExist) {
+	if bt == Studio {
+		studio := filepath.Join(dir, "StudioAppSettings.json")
+		if _, err := os.Stat(studio); err == nil {
+			return studio
+		}
+	}
+
+	return filepath.Join(dir, "ClientAppSettings.json")
+}
+
+// Validate checks that the FFlags' values are only booleans, integers
+// or strings, which are the only types of FFlags Roblox has.
+func (f FFlags) Validate() error {
+	for name, v := range f {
+		switch v.(type) {
+		case bool, string, int, int64:
+		default:
+			return fmt.Errorf("%w: %s = %v", ErrBadFFlagValue, name, v)
+		}
+	}
+
+	return nil
+}
+
+// ReadFFlags reads the FFlags file at the named path.
+func ReadFFlags(path string) (FFlags, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var f FFlags
+	d := json.NewDecoder(file)
+	d.UseNumber() // Keep integers as-is
+	if err := d.Decode(&f); err != nil {
+		return nil, fmt.Errorf("fflags %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Merge is like Apply, but keeps the FFlags already present in the file
+// at the named path, overriding them with the FFlags. FFlags named by
+// stale, such as those previously applied, are removed from the file
+// beforehand.
+func (f FFlags) Merge(path string, stale ...string) error {
+	ff, err := ReadFFlags(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if ff == nil {
+		ff = make(FFlags, len(f))
+	}
+
+	for _, name := range stale {
+		delete(ff, name)
+	}
+	maps.Copy(ff, f)
+
+	return ff.Apply(path)
+}
+
+// Apply creates and compiles the FFlags file at the named
+// path, creating its parent directories if necessary.
+func (f FFlags) Apply(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
 
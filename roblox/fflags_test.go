@@ -1,8 +1,10 @@
 package roblox
 
 import (
+	"encoding/json"
 	"errors"
 	"maps"
+	"path/filepath"
 	"testing"
 )
 
@@ -51,3 +53,56 @@ func TestFFlagRenderer(t *testing.T) {
 		t.Error("expected fflag set renderer vulkan to match expected vulkan set")
 	}
 }
+
+func TestSettingsFile(t *testing.T) {
+	dir := t.TempDir()
+	client := filepath.Join(dir, "ClientSettings", "ClientAppSettings.json")
+	studio := filepath.Join(dir, "ClientSettings", "StudioAppSettings.json")
+
+	if p := SettingsFile(Player, dir); p != client {
+		t.Errorf("expected player settings file %s, got %s", client, p)
+	}
+
+	if p := SettingsFile(Studio, dir); p != client {
+		t.Errorf("expected studio fallback settings file %s, got %s", client, p)
+	}
+
+	if err := (FFlags{}).Apply(studio); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := SettingsFile(Studio, dir); p != studio {
+		t.Errorf("expected studio settings file %s, got %s", studio, p)
+	}
+}
+
+func TestFFlagsMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ClientAppSettings.json")
+
+	if err := (FFlags{"FFlagUser": true, "FIntStale": 1}).Apply(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (FFlags{"DFIntTaskSchedulerTargetFps": 640}).Merge(path, "FIntStale"); err != nil {
+		t.Fatal(err)
+	}
+
+	ff, err := ReadFFlags(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ff) != 2 || ff["FFlagUser"] != true || ff["DFIntTaskSchedulerTargetFps"] != json.Number("640") {
+		t.Errorf("unexpected merged fflags %v", ff)
+	}
+}
+
+func TestFFlagsValidate(t *testing.T) {
+	if err := (FFlags{"FFlagA": true, "FIntB": int64(1), "FStringC": "c"}).Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if err := (FFlags{"FIntA": 1.5}).Validate(); !errors.Is(err, ErrBadFFlagValue) {
+		t.Error("expected bad fflag value check")
+	}
+}
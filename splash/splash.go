@@ -5,11 +5,16 @@ import (
 	"bytes"
 	_ "embed"
 	"errors"
+	"fmt"
 	"image"
+	_ "image/gif"
+	_ "image/jpeg"
 	_ "image/png"
 	"io"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/font/gofont"
@@ -38,6 +43,72 @@ type Config struct {
 	AccentColor uint32 `toml:"accent"`      // Color for progress bar's track and ShowLog button
 	TrackColor  uint32 `toml:"track,gray1"` // Color for the progress bar's background
 	InfoColor   uint32 `toml:"info,gray2"`  // Foreground color for the text containing binary information
+	Width       int    `toml:"width"`       // Window width, the style's width if zero
+	Height      int    `toml:"height"`      // Window height, the style's height if zero
+	Position    string `toml:"position"`    // Window position, either 'center' or 'none' to let the window manager decide
+
+	// RedrawInterval is the minimum amount of milliseconds between
+	// redraws caused by progress and message updates, which are coalesced
+	// into one redraw of the latest state, for remote desktops and
+	// low-power hardware. Redraws are not limited if zero.
+	RedrawInterval int `toml:"redraw_interval"`
+}
+
+var (
+	ErrBadSize     = errors.New("splash size must be between 0 and 4096")
+	ErrBadPosition = errors.New("splash position must be either center or none")
+	ErrBadLogo     = errors.New("splash logo must be a PNG, JPEG or GIF image")
+	ErrBadRedraw   = errors.New("splash redraw interval cannot be negative")
+)
+
+// Validate checks the splash window's size and position.
+//
+// Positioning a window on a specific monitor is not possible, as it is
+// unsupported by Gio and by Wayland compositors.
+func (c *Config) Validate() error {
+	for _, d := range []int{c.Width, c.Height} {
+		if d < 0 || d > 4096 {
+			return fmt.Errorf("%w: %d", ErrBadSize, d)
+		}
+	}
+
+	switch c.Position {
+	case "", "center", "none":
+	default:
+		return fmt.Errorf("%w: %s", ErrBadPosition, c.Position)
+	}
+
+	if c.RedrawInterval < 0 {
+		return fmt.Errorf("%w: %d", ErrBadRedraw, c.RedrawInterval)
+	}
+
+	return nil
+}
+
+// CheckLogo checks that the configured logo file is readable and is an
+// image of a supported format, returning the format. If no logo file is
+// configured, the embedded Vinegar logo is used and "png" is returned.
+func (c *Config) CheckLogo() (string, error) {
+	if c.LogoPath == "" {
+		return "png", nil
+	}
+
+	f, err := os.Open(c.LogoPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	ic, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrBadLogo, c.LogoPath, err)
+	}
+
+	if ic.Width > 4096 || ic.Height > 4096 {
+		return "", fmt.Errorf("%w: %s is larger than 4096x4096", ErrBadLogo, c.LogoPath)
+	}
+
+	return format, nil
 }
 
 type Splash struct {
@@ -55,6 +126,10 @@ type Splash struct {
 	progress float32
 	closed   bool
 
+	redrawMu      sync.Mutex
+	redrawPending bool
+	lastRedraw    time.Time
+
 	exitButton    *widget.Clickable
 	openLogButton *widget.Clickable
 }
@@ -65,7 +140,7 @@ func (ui *Splash) SetMessage(msg string) {
 	}
 
 	ui.message = msg
-	ui.Invalidate()
+	ui.invalidate()
 }
 
 func (ui *Splash) SetDesc(desc string) {
@@ -74,7 +149,7 @@ func (ui *Splash) SetDesc(desc string) {
 	}
 
 	ui.desc = desc
-	ui.Invalidate()
+	ui.invalidate()
 }
 
 func (ui *Splash) SetProgress(progress float32) {
@@ -83,7 +158,43 @@ func (ui *Splash) SetProgress(progress float32) {
 	}
 
 	ui.progress = progress
-	ui.Invalidate()
+	ui.invalidate()
+}
+
+// invalidate requests a redraw of the window, limited by the configured
+// redraw interval. Redraws requested too early are deferred to the end of
+// the interval, where only one redraw of the latest state takes place, so
+// that the final state is always drawn.
+func (ui *Splash) invalidate() {
+	interval := time.Duration(ui.Config.RedrawInterval) * time.Millisecond
+	if interval <= 0 {
+		ui.Invalidate()
+		return
+	}
+
+	ui.redrawMu.Lock()
+	defer ui.redrawMu.Unlock()
+
+	if ui.redrawPending {
+		return
+	}
+
+	wait := interval - time.Since(ui.lastRedraw)
+	if wait <= 0 {
+		ui.lastRedraw = time.Now()
+		ui.Invalidate()
+		return
+	}
+
+	ui.redrawPending = true
+	time.AfterFunc(wait, func() {
+		ui.redrawMu.Lock()
+		ui.redrawPending = false
+		ui.lastRedraw = time.Now()
+		ui.redrawMu.Unlock()
+
+		ui.Invalidate()
+	})
 }
 
 func (ui *Splash) Close() {
@@ -123,8 +234,18 @@ func New(cfg *Config) *Splash {
 		s = Familiar
 	}
 
-	w := window(s.Size())
-	w.Perform(system.ActionCenter)
+	width, height := s.Size()
+	if cfg.Width > 0 {
+		width = unit.Dp(cfg.Width)
+	}
+	if cfg.Height > 0 {
+		height = unit.Dp(cfg.Height)
+	}
+
+	w := window(width, height)
+	if cfg.Position != "none" {
+		w.Perform(system.ActionCenter)
+	}
 
 	th := material.NewTheme()
 	th.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
@@ -148,18 +269,20 @@ func New(cfg *Config) *Splash {
 	}
 }
 
+// loadLogo loads the configured logo file, falling back to the
+// embedded Vinegar logo if it is missing or invalid.
 func (ui *Splash) loadLogo() error {
-	var r io.Reader
+	var r io.Reader = bytes.NewReader(vinegarLogo)
 
-	if ui.Config.LogoPath != "" {
+	if _, err := ui.Config.CheckLogo(); err != nil {
+		log.Println("Using default logo, failed to load logo:", err)
+	} else if ui.Config.LogoPath != "" {
 		lf, err := os.Open(ui.Config.LogoPath)
 		if err != nil {
 			return err
 		}
 		defer lf.Close()
 		r = lf
-	} else {
-		r = bytes.NewReader(vinegarLogo)
 	}
 
 	logo, _, err := image.Decode(r)
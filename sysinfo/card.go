@@ -10,6 +10,8 @@ type Card struct {
 	Path     string // Path to the drm card
 	Device   string // Path to the PCI device
 	Driver   string // Base driver name
+	Vendor   string // PCI vendor ID, such as 0x10de
+	Product  string // PCI device ID
 	Embedded bool   // Integrated display
 }
 
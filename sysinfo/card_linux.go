@@ -28,6 +28,8 @@ func getCards() (cs []Card) {
 			Path:     c,
 			Device:   dev,
 			Driver:   driver,
+			Vendor:   readID(path.Join(dev, "vendor")),
+			Product:  readID(path.Join(dev, "device")),
 			Embedded: embedded(c),
 		})
 	}
@@ -35,6 +37,13 @@ func getCards() (cs []Card) {
 	return
 }
 
+// readID reads the PCI ID in the named sysfs file, which is
+// empty for devices that are not PCI devices.
+func readID(name string) string {
+	b, _ := os.ReadFile(name)
+	return strings.TrimSpace(string(b))
+}
+
 // Walks over the drm path, and checks if there are any displays
 // that are matched with the card path and contain any of embeddedDisplays
 func embedded(cardPath string) (embed bool) {
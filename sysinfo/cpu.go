@@ -5,4 +5,5 @@ type Processor struct {
 	Name            string
 	AVX             bool
 	SplitLockDetect bool
+	Threads         int // Logical processors usable by Vinegar
 }
@@ -6,6 +6,7 @@ import (
 	"bufio"
 	"os"
 	"regexp"
+	"runtime"
 	"strings"
 
 	cpulib "golang.org/x/sys/cpu"
@@ -13,8 +14,9 @@ import (
 
 func getCPU() Processor {
 	c := Processor{
-		Name: "unknown cpu",
-		AVX:  cpulib.X86.HasAVX,
+		Name:    "unknown cpu",
+		AVX:     cpulib.X86.HasAVX,
+		Threads: runtime.NumCPU(),
 	}
 
 	column := regexp.MustCompile("\t+: ")
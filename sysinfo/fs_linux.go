@@ -0,0 +1,117 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Filesystem returns the type of the filesystem that the named path
+// resides on, such as 'ext4' or 'fuseblk', as reported by the kernel's
+// mount table.
+func Filesystem(name string) (string, error) {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	if p, err := filepath.EvalSymlinks(name); err == nil {
+		name = p
+	}
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var mount, fstype string
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		pre, post, ok := strings.Cut(s.Text(), " - ")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(pre)
+		postFields := strings.Fields(post)
+		if len(fields) < 5 || len(postFields) < 1 {
+			continue
+		}
+
+		mp := unescapeMount(fields[4])
+		if !within(name, mp) || len(mp) < len(mount) {
+			continue
+		}
+
+		mount, fstype = mp, postFields[0]
+	}
+
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+
+	if fstype == "" {
+		return "", fmt.Errorf("no mount found for %s", name)
+	}
+
+	return fstype, nil
+}
+
+func within(name, dir string) bool {
+	return dir == "/" || name == dir || strings.HasPrefix(name, dir+"/")
+}
+
+// unescapeMount decodes the octal escapes used for spaces and
+// other special characters in the mount table.
+func unescapeMount(s string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+
+	return sb.String()
+}
+
+// Rotational determines if the named path resides on a rotational
+// block device, such as a hard disk drive. Paths on devices that cannot
+// be determined, such as network filesystems, are not rotational.
+func Rotational(name string) bool {
+	var st unix.Stat_t
+	if err := unix.Stat(name, &st); err != nil {
+		return false
+	}
+
+	dev, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/dev/block/%d:%d",
+		unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev))))
+	if err != nil {
+		return false
+	}
+
+	// Partitions have no queue of their own, it is the parent device's.
+	for _, d := range []string{dev, filepath.Dir(dev)} {
+		r, err := os.ReadFile(filepath.Join(d, "queue", "rotational"))
+		if err == nil {
+			return strings.TrimSpace(string(r)) == "1"
+		}
+	}
+
+	return false
+}
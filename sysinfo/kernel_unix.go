@@ -1,6 +1,7 @@
 package sysinfo
 
 import (
+	"fmt"
 	"strings"
 
 	"golang.org/x/sys/unix"
@@ -19,3 +20,14 @@ func getKernel() string {
 	}
 	return sb.String()
 }
+
+// KernelAtLeast determines if the running kernel's version
+// is at least the given major and minor version.
+func KernelAtLeast(major, minor int) bool {
+	var maj, mnr int
+	if _, err := fmt.Sscanf(Kernel, "%d.%d", &maj, &mnr); err != nil {
+		return false
+	}
+
+	return maj > major || (maj == major && mnr >= minor)
+}
@@ -0,0 +1,23 @@
+package sysinfo
+
+import (
+	"os"
+)
+
+// getSession determines the type of the graphical session, preferring
+// the session manager's declared type over the available displays.
+func getSession() string {
+	if t := os.Getenv("XDG_SESSION_TYPE"); t == "wayland" || t == "x11" {
+		return t
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return "wayland"
+	}
+
+	if os.Getenv("DISPLAY") != "" {
+		return "x11"
+	}
+
+	return "unknown"
+}
@@ -8,17 +8,21 @@ import (
 
 var (
 	Kernel    string
+	Futex2    bool // futex_waitv(2) support, as required by Wine's fsync
 	CPU       Processor
 	Cards     []Card
 	Distro    string
+	Session   string // Graphical session type, either wayland, x11 or unknown
 	InFlatpak bool
 )
 
 func init() {
 	Kernel = getKernel()
+	Futex2 = KernelAtLeast(5, 16)
 	CPU = getCPU()
 	Cards = getCards()
 	Distro = getDistro()
+	Session = getSession()
 
 	_, err := os.Stat("/.flatpak-info")
 	InFlatpak = err == nil
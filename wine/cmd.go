@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 )
 
 type Cmd struct {
@@ -26,9 +29,22 @@ type Cmd struct {
 // For further information regarding Command, refer to [exec.Command].
 func (p *Prefix) Command(name string, arg ...string) *Cmd {
 	cmd := exec.Command(name, arg...)
-	cmd.Env = append(cmd.Environ(),
-		"WINEPREFIX="+p.dir,
-	)
+
+	// Every Prefix has it's own wineserver, determined by the Prefix's
+	// directory and the Wine installation, or the Prefix's Server.
+	cmd.Env = slices.DeleteFunc(cmd.Environ(), func(e string) bool {
+		return strings.HasPrefix(e, "WINESERVER=")
+	})
+	cmd.Env = append(cmd.Env, "WINEPREFIX="+p.dir)
+	if p.Server != "" {
+		cmd.Env = append(cmd.Env, "WINESERVER="+p.Server)
+	}
+	if p.Proton() {
+		cmd.Env = append(cmd.Env,
+			"STEAM_COMPAT_DATA_PATH="+p.compat,
+			"STEAM_COMPAT_CLIENT_INSTALL_PATH="+SteamClientDir(),
+		)
+	}
 
 	cmd.Stderr = p.Stderr
 	cmd.Stdout = p.Stdout
@@ -64,7 +80,7 @@ func (c *Cmd) Start() error {
 		go func() {
 			_, err := io.Copy(pfxStderr, cmdErrPipe)
 			if err != nil && !errors.Is(err, fs.ErrClosed) {
-				panic(err)
+				slog.Error("Failed to copy wine stderr", "error", err)
 			}
 		}()
 	}
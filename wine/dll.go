@@ -0,0 +1,130 @@
+package wine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrBadDLL = errors.New("dll is invalid")
+
+// dllDirs maps the architecture directory names used by DXVK and
+// VKD3D-Proton releases to the Wineprefix system directory their DLLs
+// are installed to.
+var dllDirs = map[string]string{
+	"x64": "system32",
+	"x32": "syswow64",
+	"x86": "syswow64",
+}
+
+// InstallDLLs installs the DLLs within the architecture directories of
+// the named .tar.gz archive or directory, such as a DXVK release, into
+// the Prefix's system directories.
+func (p *Prefix) InstallDLLs(src string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		return filepath.WalkDir(src, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			return p.installDLL(name, f)
+		})
+	}
+
+	if !strings.HasSuffix(src, ".tar.gz") {
+		return fmt.Errorf("%s: only .tar.gz archives are supported, extract it instead", src)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := p.installDLL(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// installDLL writes the DLL read from r to the Prefix's system directory
+// matching the architecture directory of the named file, skipping
+// files that are not DLLs or not within an architecture directory.
+func (p *Prefix) installDLL(name string, r io.Reader) error {
+	sys, ok := dllDirs[path.Base(path.Dir(filepath.ToSlash(name)))]
+	if !ok || path.Ext(name) != ".dll" {
+		return nil
+	}
+
+	dir := filepath.Join(p.dir, "drive_c", "windows", sys)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	dst := filepath.Join(dir, path.Base(filepath.ToSlash(name)))
+	slog.Info("Installing DLL", "path", dst)
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// VerifyDLLs checks that the named DLLs, without their extension,
+// are present and not empty in the Prefix's 64-bit system directory.
+func (p *Prefix) VerifyDLLs(names ...string) error {
+	for _, n := range names {
+		fi, err := os.Stat(filepath.Join(p.dir, "drive_c", "windows", "system32", n+".dll"))
+		if err != nil {
+			return err
+		}
+
+		if fi.Size() == 0 {
+			return fmt.Errorf("%w: %s.dll is empty", ErrBadDLL, n)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ package dxvk
 import (
 	"archive/tar"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -17,6 +18,27 @@ import (
 
 const Repo = "https://github.com/doitsujin/dxvk"
 
+// OverridesKey is the registry key holding Wine's DLL overrides.
+const OverridesKey = `HKEY_CURRENT_USER\Software\Wine\DllOverrides`
+
+// DLLs is the list of DLLs that DXVK overrides.
+var DLLs = []string{"d3d9", "d3d10core", "d3d11", "dxgi"}
+
+// SetOverrides sets the Prefix's registry DLL overrides of the DXVK DLLs
+// to the named mode, either 'native' to use DXVK or 'builtin' to use
+// Wine's own DLLs, so that DXVK is used by all of the Prefix's programs.
+func SetOverrides(pfx *wine.Prefix, mode string) error {
+	slog.Info("Setting DXVK DLL overrides", "pfx", pfx, "mode", mode)
+
+	for _, dll := range DLLs {
+		if err := pfx.RegistryAdd(OverridesKey, dll, wine.REG_SZ, mode); err != nil {
+			return fmt.Errorf("override %s: %w", dll, err)
+		}
+	}
+
+	return nil
+}
+
 // Setenv sets/appends WINEDLLOVERRIDES to tell Wine to use the DXVK DLLs.
 //
 // This is required to call inorder to tell Wine to use DXVK.
@@ -26,21 +48,27 @@ func Setenv() {
 	os.Setenv("WINEDLLOVERRIDES", os.Getenv("WINEDLLOVERRIDES")+";d3d10core=n;d3d11=n;d3d9=n;dxgi=n")
 }
 
+// Remove deletes the DXVK DLLs from the Prefix and sets their registry
+// overrides back to builtin, then runs wineboot to reinstall Wine's DLLs.
 func Remove(pfx *wine.Prefix) error {
 	slog.Info("Deleting DXVK DLLs", "pfx", pfx)
 
 	for _, dir := range []string{"syswow64", "system32"} {
-		for _, dll := range []string{"d3d9", "d3d10core", "d3d11", "dxgi"} {
+		for _, dll := range DLLs {
 			p := filepath.Join(pfx.Dir(), "drive_c", "windows", dir, dll+".dll")
 
 			slog.Info("Removing DXVK overriden Wine DLL", "path", p)
 
-			if err := os.Remove(p); err != nil {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
 				return err
 			}
 		}
 	}
 
+	if err := SetOverrides(pfx, "builtin"); err != nil {
+		return err
+	}
+
 	slog.Info("Restoring Wineprefix DLLs", "pfx", pfx)
 
 	return pfx.Wine("wineboot", "-u").Run()
@@ -48,7 +76,8 @@ func Remove(pfx *wine.Prefix) error {
 
 // Install will download the DXVK tarball with the given version to a temporary
 // file dictated by os.CreateTemp. Afterwards, it will proceed by calling Extract
-// with the DXVK tarball, and then removing it.
+// with the DXVK tarball, and then removing it. Once the DLLs have been verified,
+// their registry overrides are set to use them.
 func Install(ver string, pfx *wine.Prefix) error {
 	url := fmt.Sprintf("%s/releases/download/v%[2]s/dxvk-%[2]s.tar.gz", Repo, ver)
 	f, err := os.CreateTemp("", "dxvktarball.*.tar.gz")
@@ -67,7 +96,27 @@ func Install(ver string, pfx *wine.Prefix) error {
 		return fmt.Errorf("extract dxvk %s: %w", ver, err)
 	}
 
-	return nil
+	if err := pfx.VerifyDLLs(DLLs...); err != nil {
+		return err
+	}
+
+	return SetOverrides(pfx, "native")
+}
+
+// InstallFrom installs DXVK from the named release .tar.gz
+// archive or directory, and verifies its DLLs.
+func InstallFrom(src string, pfx *wine.Prefix) error {
+	slog.Info("Installing DXVK", "src", src, "pfx", pfx)
+
+	if err := pfx.InstallDLLs(src); err != nil {
+		return err
+	}
+
+	if err := pfx.VerifyDLLs(DLLs...); err != nil {
+		return err
+	}
+
+	return SetOverrides(pfx, "native")
 }
 
 func Extract(name string, pfx *wine.Prefix) error {
@@ -0,0 +1,98 @@
+package wine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// registryHeader is the first line of every registry file written by Wine.
+const registryHeader = "WINE REGISTRY Version 2"
+
+var ErrRegistryCorrupt = errors.New("registry file is corrupted")
+
+// registryFiles maps the registry files of a Prefix to a key that
+// is always present within them once the Prefix is initialized.
+var registryFiles = map[string]string{
+	"system.reg": `Software\\Microsoft\\Windows NT\\CurrentVersion`,
+	"user.reg":   `Control Panel\\Desktop`,
+}
+
+// CheckRegistry checks that the Prefix's registry files can be parsed
+// and contain the keys expected of an initialized Prefix, returning
+// [ErrRegistryCorrupt] otherwise. The registry is only read from disk,
+// hence it should not be running.
+func (p *Prefix) CheckRegistry() error {
+	for name, key := range registryFiles {
+		if err := checkRegistryFile(filepath.Join(p.dir, name), key); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func checkRegistryFile(name, key string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+
+	if !s.Scan() || s.Text() != registryHeader {
+		return fmt.Errorf("%w: missing header", ErrRegistryCorrupt)
+	}
+
+	found := false
+	cont := false
+	n := 1
+
+	for s.Scan() {
+		n++
+		line := s.Text()
+
+		// Values may span multiple lines, ending with a backslash.
+		if cont {
+			cont = strings.HasSuffix(line, "\\")
+			continue
+		}
+
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			k, _, ok := strings.Cut(line[1:], "]")
+			if !ok {
+				return fmt.Errorf("%w: bad key on line %d", ErrRegistryCorrupt, n)
+			}
+			if strings.EqualFold(k, key) {
+				found = true
+			}
+		case strings.HasPrefix(line, `"`), strings.HasPrefix(line, "@"):
+			if !strings.Contains(line, "=") {
+				return fmt.Errorf("%w: bad value on line %d", ErrRegistryCorrupt, n)
+			}
+			cont = strings.HasSuffix(line, "\\")
+		default:
+			return fmt.Errorf("%w: unexpected line %d", ErrRegistryCorrupt, n)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	if cont {
+		return fmt.Errorf("%w: truncated", ErrRegistryCorrupt)
+	}
+
+	if !found {
+		return fmt.Errorf("%w: missing key %s", ErrRegistryCorrupt, key)
+	}
+
+	return nil
+}
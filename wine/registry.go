@@ -2,8 +2,13 @@ package wine
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 )
 
+var ErrRegistryValueNotFound = errors.New("registry value not found")
+
 // RegistryType is the type of registry that the wine 'reg' program
 // can accept.
 type RegistryType string
@@ -26,3 +31,58 @@ func (p *Prefix) RegistryAdd(key, value string, rtype RegistryType, data string)
 
 	return p.Wine("reg", "add", key, "/v", value, "/t", string(rtype), "/d", data, "/f").Run()
 }
+
+// RegistryQuery returns the data of the named value of the named
+// registry key in the Prefix, as formatted by the wine 'reg' program.
+func (p *Prefix) RegistryQuery(key, value string) (string, error) {
+	if key == "" {
+		return "", errors.New("no registry key given")
+	}
+
+	cmd := p.Wine("reg", "query", key, "/v", value)
+	cmd.Stdout = nil // required for Output()
+	cmd.Stderr = nil
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	// HKEY_CURRENT_USER\Control Panel\Desktop
+	//     LogPixels    REG_DWORD    0x61
+	for _, l := range strings.Split(string(out), "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(l), value)
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "REG_") {
+			return strings.Join(fields[1:], " "), nil
+		}
+	}
+
+	return "", ErrRegistryValueNotFound
+}
+
+// RegistryDelete deletes the named value of the named registry key in the Prefix.
+func (p *Prefix) RegistryDelete(key, value string) error {
+	if key == "" {
+		return errors.New("no registry key given")
+	}
+
+	return p.Wine("reg", "delete", key, "/v", value, "/f").Run()
+}
+
+// RegistryImport imports the named registry file into the Prefix with
+// regedit. Unlike RegistryAdd, the data is not passed in the arguments
+// of a process, which makes it suitable for secrets. The named file
+// must reside within the Prefix's C: drive.
+func (p *Prefix) RegistryImport(name string) error {
+	rel, err := filepath.Rel(filepath.Join(p.dir, "drive_c"), name)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("registry file %s is not within the prefix", name)
+	}
+
+	return p.Wine("regedit", "/S", `C:\`+strings.ReplaceAll(rel, "/", `\`)).Run()
+}
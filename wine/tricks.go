@@ -1,15 +1,62 @@
 package wine
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"strconv"
 )
 
+const desktopKey = "HKEY_CURRENT_USER\\Control Panel\\Desktop"
+
+var ErrDPIMismatch = errors.New("wineprefix dpi was not set")
+
 // Winetricks runs winetricks within the Prefix.
 func (p *Prefix) Winetricks() error {
 	return p.Command("winetricks").Run()
 }
 
-// SetDPI sets the Prefix's DPI to the named DPI.
+// DPI returns the Prefix's DPI.
+func (p *Prefix) DPI() (int, error) {
+	data, err := p.RegistryQuery(desktopKey, "LogPixels")
+	if err != nil {
+		return 0, err
+	}
+
+	dpi, err := strconv.ParseInt(data, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse dpi: %w", err)
+	}
+
+	return int(dpi), nil
+}
+
+// SetDPI sets the Prefix's DPI to the named DPI, and verifies it has been
+// set by reading it back from the registry, retrying once if it had not.
 func (p *Prefix) SetDPI(dpi int) error {
-	return p.RegistryAdd("HKEY_CURRENT_USER\\Control Panel\\Desktop", "LogPixels", REG_DWORD, strconv.Itoa(dpi))
+	before, _ := p.DPI()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			slog.Warn("Setting Wineprefix DPI failed, retrying", "error", err)
+		}
+
+		err = p.RegistryAdd(desktopKey, "LogPixels", REG_DWORD, strconv.Itoa(dpi))
+		if err != nil {
+			continue
+		}
+
+		var after int
+		after, err = p.DPI()
+		if err == nil && after != dpi {
+			err = fmt.Errorf("%w: %d, expected %d", ErrDPIMismatch, after, dpi)
+		}
+		if err == nil {
+			slog.Info("Set Wineprefix DPI", "before", before, "after", after)
+			return nil
+		}
+	}
+
+	return err
 }
@@ -1,16 +1,56 @@
 package wine
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"os/user"
 	"path/filepath"
+	"time"
 )
 
+// AppDataWait is how long AppDataDir waits for Wine to create the
+// user's directory within an initialized Prefix.
+const AppDataWait = 5 * time.Second
+
+var ErrPrefixNotInit = errors.New("wineprefix is not initialized")
+
+// appDataDirs is a list of the directories Wine creates within AppData.
+var appDataDirs = []string{"Local", "LocalLow", "Roaming"}
+
 // AppDataDir returns the current user's AppData within the Prefix.
+//
+// If the Prefix has been initialized but Wine has not yet created the
+// user's directory, such as while the Prefix is still booting, it is
+// waited for up to [AppDataWait], after which it is created instead.
 func (p *Prefix) AppDataDir() (string, error) {
 	user, err := user.Current()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(p.dir, "drive_c", "users", user.Username, "AppData"), nil
+	dir := filepath.Join(p.dir, "drive_c", "users", user.Username, "AppData")
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(p.dir, "system.reg")); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrPrefixNotInit, p.dir)
+	}
+
+	for deadline := time.Now().Add(AppDataWait); time.Now().Before(deadline); {
+		time.Sleep(250 * time.Millisecond)
+
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+
+	for _, d := range appDataDirs {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			return "", fmt.Errorf("create appdata: %w", err)
+		}
+	}
+
+	return dir, nil
 }
@@ -0,0 +1,84 @@
+package wine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var ErrBadVersion = errors.New("unrecognized wine version")
+
+// Version is a Wine version, as reported by 'wine --version'.
+type Version struct {
+	Major   int
+	Minor   int
+	Staging bool
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("wine-%d.%d", v.Major, v.Minor)
+	if v.Staging {
+		s += " (Staging)"
+	}
+
+	return s
+}
+
+// Less determines if the version is older than the other version.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+
+	return v.Minor < o.Minor
+}
+
+// ParseVersion parses the named Wine version string, such as
+// 'wine-9.0', 'wine-9.0-rc1' or 'wine-8.21 (Staging)'.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	s = strings.TrimSpace(s)
+	ver, extra, _ := strings.Cut(s, " ")
+	ver, ok := strings.CutPrefix(ver, "wine-")
+	if !ok {
+		return v, fmt.Errorf("%w: %s", ErrBadVersion, s)
+	}
+
+	if _, err := fmt.Sscanf(ver, "%d.%d", &v.Major, &v.Minor); err != nil {
+		return v, fmt.Errorf("%w: %s", ErrBadVersion, s)
+	}
+
+	v.Staging = strings.Contains(strings.ToLower(extra), "staging")
+	return v, nil
+}
+
+// Version returns the Prefix's Wine version. The version is
+// only retrieved once, and is reused in subsequent calls.
+func (p *Prefix) Version() (Version, error) {
+	if p.version != nil {
+		return *p.version, nil
+	}
+
+	cmd := p.Wine("--version")
+	// Proton has no version command, use its Wine installation instead.
+	if p.Proton() {
+		cmd = p.Command(filepath.Join(p.Root, "files", "bin", "wine"), "--version")
+	}
+	cmd.Stdout = nil // required for Output()
+	cmd.Stderr = nil
+
+	out, err := cmd.Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("wine version: %w", err)
+	}
+
+	v, err := ParseVersion(string(out))
+	if err != nil {
+		return v, err
+	}
+
+	p.version = &v
+	return v, nil
+}
@@ -0,0 +1,28 @@
+package wine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for s, want := range map[string]Version{
+		"wine-9.0\n":            {Major: 9, Minor: 0},
+		"wine-9.0-rc1":          {Major: 9, Minor: 0},
+		"wine-8.21 (Staging)\n": {Major: 8, Minor: 21, Staging: true},
+		"wine-7.0.1":            {Major: 7, Minor: 0},
+	} {
+		v, err := ParseVersion(s)
+		if err != nil || v != want {
+			t.Errorf("parse %q: got %v, want %v: %v", s, v, want, err)
+		}
+	}
+
+	if _, err := ParseVersion("proton-8.0-5"); !errors.Is(err, ErrBadVersion) {
+		t.Error("expected bad version")
+	}
+
+	if !(Version{Major: 8, Minor: 21}).Less(Version{Major: 9}) {
+		t.Error("expected 8.21 to be older than 9.0")
+	}
+}
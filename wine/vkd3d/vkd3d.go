@@ -0,0 +1,49 @@
+// Package vkd3d implements routines to install VKD3D-Proton to a given [wine.Prefix]
+package vkd3d
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/vinegarhq/vinegar/wine"
+)
+
+// DLLs is the list of DLLs that VKD3D-Proton overrides.
+var DLLs = []string{"d3d12", "d3d12core"}
+
+// Setenv sets/appends WINEDLLOVERRIDES to tell Wine to use the VKD3D-Proton DLLs.
+func Setenv() {
+	slog.Info("Enabling WINE VKD3D DLL overrides")
+
+	os.Setenv("WINEDLLOVERRIDES", os.Getenv("WINEDLLOVERRIDES")+";d3d12=n;d3d12core=n")
+}
+
+// Install installs VKD3D-Proton from the named release
+// .tar.gz archive or directory, and verifies its DLLs.
+func Install(src string, pfx *wine.Prefix) error {
+	slog.Info("Installing VKD3D-Proton", "src", src, "pfx", pfx)
+
+	if err := pfx.InstallDLLs(src); err != nil {
+		return err
+	}
+
+	return pfx.VerifyDLLs(DLLs...)
+}
+
+// Remove removes the VKD3D-Proton DLLs and restores Wine's own.
+func Remove(pfx *wine.Prefix) error {
+	slog.Info("Deleting VKD3D-Proton DLLs", "pfx", pfx)
+
+	for _, dir := range []string{"syswow64", "system32"} {
+		for _, dll := range DLLs {
+			p := filepath.Join(pfx.Dir(), "drive_c", "windows", dir, dll+".dll")
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return pfx.Wine("wineboot", "-u").Run()
+}
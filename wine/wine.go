@@ -24,6 +24,10 @@ type Prefix struct {
 	// Path to a wine installation.
 	Root string
 
+	// Path to the wineserver used by the Prefix, the Wine installation's
+	// own if empty. A wineserver from the environment is never used.
+	Server string
+
 	// Stdout and Stderr specify the descendant Prefix wine call's
 	// standard output and error. This is mostly reserved for logging purposes.
 	// By default, they will be set to their os counterparts.
@@ -32,15 +36,55 @@ type Prefix struct {
 
 	wine string
 	dir  string
+
+	// Steam compatibility data directory containing the Prefix
+	// in 'pfx', if the Prefix is managed by Proton.
+	compat string
+
+	version *Version
 }
 
 func (p Prefix) String() string {
 	return p.dir
 }
 
+// IsProton determines if the named wineroot is a Proton installation,
+// which has a 'proton' script instead of a 'bin/wine'.
+func IsProton(root string) bool {
+	if root == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(root, "proton"))
+	return err == nil
+}
+
+// SteamClientDir returns the Steam installation directory used by Proton,
+// from the environment if set by Steam, otherwise from the usual locations.
+// If Steam is not installed, an empty string is returned.
+func SteamClientDir() string {
+	if dir, ok := os.LookupEnv("STEAM_COMPAT_CLIENT_INSTALL_PATH"); ok {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, dir := range []string{
+		filepath.Join(home, ".steam", "root"),
+		filepath.Join(home, ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+	} {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+
+	return ""
+}
+
 // Wine64 returns a path to the system or wineroot's 'wine64'.
-// Wine64 will attempt to resolve for a [ULWGL launcher] if
-// it is present and set necessary environment variables.
+// Wine64 will attempt to resolve for a [ULWGL launcher] or a
+// Proton installation's 'proton' script if it is present and
+// set necessary environment variables.
 //
 // [ULWGL launcher]: https://github.com/Open-Wine-Components/ULWGL-launcher
 func Wine64(root string) (string, error) {
@@ -51,7 +95,11 @@ func Wine64(root string) (string, error) {
 			return "", ErrWineRootAbs
 		}
 
-		if strings.Contains(strings.ToLower(root), "ulwgl") {
+		if IsProton(root) {
+			slog.Info("Detected Proton Wineroot!")
+
+			wineLook = filepath.Join(root, "proton")
+		} else if strings.Contains(strings.ToLower(root), "ulwgl") {
 			slog.Info("Detected ULWGL Wineroot!")
 
 			wineLook = filepath.Join(root, "ulwgl-run")
@@ -76,7 +124,9 @@ func Wine64(root string) (string, error) {
 // if the wine executable changes it will not be re-looked.
 //
 // dir must be an absolute path and has correct permissions
-// to modify.
+// to modify. If root is a Proton installation, dir is used as
+// Proton's compatibility data directory, and the Prefix will
+// reside within it in 'pfx', as managed by Proton.
 func New(dir string, root string) (*Prefix, error) {
 	w, err := Wine64(root)
 	if err != nil {
@@ -89,13 +139,25 @@ func New(dir string, root string) (*Prefix, error) {
 		return nil, fmt.Errorf("create prefix: %s", err)
 	}
 
-	return &Prefix{
+	pfx := &Prefix{
 		Root:   root,
 		Stderr: os.Stderr,
 		Stdout: os.Stdout,
 		wine:   w,
 		dir:    dir,
-	}, nil
+	}
+
+	if IsProton(root) {
+		pfx.compat = dir
+		pfx.dir = filepath.Join(dir, "pfx")
+	}
+
+	return pfx, nil
+}
+
+// Proton determines if the Prefix is managed by Proton.
+func (p *Prefix) Proton() bool {
+	return p.compat != ""
 }
 
 // Dir returns the directory of the Prefix.
@@ -104,8 +166,12 @@ func (p *Prefix) Dir() string {
 }
 
 // Wine returns a new Cmd with the prefix's Wine as the named program.
+// If the Prefix is managed by Proton, the program is ran with 'proton run'.
 func (p *Prefix) Wine(exe string, arg ...string) *Cmd {
 	arg = append([]string{exe}, arg...)
+	if p.Proton() {
+		arg = append([]string{"run"}, arg...)
+	}
 	cmd := p.Command(p.wine, arg...)
 
 	if strings.Contains(strings.ToLower(p.wine), "ulwgl") {
@@ -129,18 +195,3 @@ func (p *Prefix) Init() error {
 func (p *Prefix) Update() error {
 	return p.Wine("wineboot", "-u").Run()
 }
-
-// Version returns the wineprefix's Wine version.
-func (p *Prefix) Version() string {
-	cmd := p.Wine("--version")
-	cmd.Stdout = nil // required for Output()
-	cmd.Stderr = nil
-
-	ver, _ := cmd.Output()
-	if len(ver) < 0 {
-		return "unknown"
-	}
-
-	// remove newline
-	return string(ver[:len(ver)-1])
-}